@@ -1,6 +1,12 @@
 package loghq
 
-import "testing"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
 
 // discardWriteSyncer wraps io.Discard as a WriteSyncer for benchmarks.
 type discardWriteSyncer struct{}
@@ -81,6 +87,16 @@ func BenchmarkWithFields(b *testing.B) {
 	}
 }
 
+func BenchmarkInfoContext(b *testing.B) {
+	l := newBenchLogger()
+	ctx := ContextWithFields(context.Background(), String("request_id", "abc-123"))
+	b.ResetTimer()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		l.InfoContext(ctx, "request", "status", 200)
+	}
+}
+
 func BenchmarkParallel(b *testing.B) {
 	l := newBenchLogger()
 	b.ResetTimer()
@@ -91,3 +107,67 @@ func BenchmarkParallel(b *testing.B) {
 		}
 	})
 }
+
+func BenchmarkSampled(b *testing.B) {
+	l := New(
+		WithHandler(NewSamplingHandler(NewJSONHandler(discardWriteSyncer{}), time.Second, 100, 100)),
+		WithLevel(InfoLevel),
+		WithCaller(false),
+		WithStackLevel(FatalLevel+1),
+	)
+	b.ResetTimer()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		l.Info("hot loop", "i", i)
+	}
+}
+
+type benchObject struct {
+	name string
+	id   int64
+}
+
+func (o *benchObject) MarshalLogObject(enc FieldEncoder) error {
+	enc.EncodeString("name", o.name)
+	enc.EncodeInt64("id", o.id)
+	return nil
+}
+
+func BenchmarkObject(b *testing.B) {
+	for _, tt := range []struct {
+		name string
+		h    Handler
+	}{
+		{"json", NewJSONHandler(discardWriteSyncer{})},
+		{"logfmt", NewLogfmtHandler(discardWriteSyncer{})},
+		{"console", NewConsoleHandler(WithConsoleWriter(discardWriteSyncer{}))},
+	} {
+		b.Run(tt.name, func(b *testing.B) {
+			l := New(WithHandler(tt.h), WithCaller(false), WithStackLevel(FatalLevel+1))
+			obj := &benchObject{name: "ali", id: 42}
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				l.Info("login", "user", obj)
+			}
+		})
+	}
+}
+
+func BenchmarkError(b *testing.B) {
+	plain := errors.New("connection refused")
+	for _, tt := range []struct {
+		name string
+		err  error
+	}{
+		{"plain", plain},
+		{"wrapped", fmt.Errorf("dial db: %w", plain)},
+	} {
+		b.Run(tt.name, func(b *testing.B) {
+			l := newBenchLogger()
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				l.Error("query failed", "error", tt.err)
+			}
+		})
+	}
+}
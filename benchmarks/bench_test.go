@@ -398,3 +398,64 @@ func BenchmarkParallel_Logrus(b *testing.B) {
 		}
 	})
 }
+
+// ============================================================
+// Custom object marshalers
+// ============================================================
+
+type benchUser struct {
+	Name  string
+	Email string
+	ID    int64
+	Roles benchRoles
+}
+
+type benchRoles []string
+
+func (u *benchUser) MarshalLogObject(enc loghq.FieldEncoder) error {
+	enc.EncodeString("name", u.Name)
+	enc.EncodeString("email", u.Email)
+	enc.EncodeInt64("id", u.ID)
+	enc.EncodeArray("roles", &u.Roles)
+	return nil
+}
+
+func (r *benchRoles) MarshalLogArray(enc loghq.ArrayEncoder) error {
+	for _, role := range *r {
+		enc.AppendString(role)
+	}
+	return nil
+}
+
+func (u *benchUser) zapObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("name", u.Name)
+	enc.AddString("email", u.Email)
+	enc.AddInt64("id", u.ID)
+	return enc.AddArray("roles", zapcore.ArrayMarshalerFunc(func(ae zapcore.ArrayEncoder) error {
+		for _, role := range u.Roles {
+			ae.AppendString(role)
+		}
+		return nil
+	}))
+}
+
+var benchUserValue = &benchUser{Name: "ali", Email: "ali@example.com", ID: 42, Roles: benchRoles{"admin", "dev"}}
+
+func BenchmarkObject_Loghq(b *testing.B) {
+	l := newLoghq()
+	b.ResetTimer()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		l.Info("login", "user", benchUserValue)
+	}
+}
+
+func BenchmarkObject_Zap(b *testing.B) {
+	l := newZap()
+	obj := zapcore.ObjectMarshalerFunc(benchUserValue.zapObject)
+	b.ResetTimer()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		l.Info("login", zap.Object("user", obj))
+	}
+}
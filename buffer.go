@@ -1,6 +1,7 @@
 package loghq
 
 import (
+	"math"
 	"strconv"
 	"sync"
 	"time"
@@ -56,6 +57,17 @@ func (b *Buffer) AppendFloat(f float64) {
 	b.B = strconv.AppendFloat(b.B, f, 'f', -1, 64)
 }
 
+// AppendComplex writes c as "real+imagi", e.g. 1.5-2i.
+func (b *Buffer) AppendComplex(c complex128) {
+	r, i := real(c), imag(c)
+	b.B = strconv.AppendFloat(b.B, r, 'g', -1, 64)
+	if i >= 0 || math.IsNaN(i) {
+		b.B = append(b.B, '+')
+	}
+	b.B = strconv.AppendFloat(b.B, i, 'g', -1, 64)
+	b.B = append(b.B, 'i')
+}
+
 func (b *Buffer) AppendBool(v bool) {
 	b.B = strconv.AppendBool(b.B, v)
 }
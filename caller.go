@@ -11,6 +11,8 @@ type CallerInfo struct {
 	File     string
 	Line     int
 	Function string
+	Package  string  // import path, e.g. "github.com/acme/app/payments"
+	PC       uintptr // program counter, as passed to slog.NewRecord
 	defined  bool
 }
 
@@ -29,36 +31,96 @@ func (c CallerInfo) Defined() bool {
 
 // captureCaller captures the caller's file and line at the given skip depth.
 func captureCaller(skip int) CallerInfo {
-	pc, file, line, ok := runtime.Caller(skip)
-	if !ok {
+	var pcs [1]uintptr
+	if runtime.Callers(skip+1, pcs[:]) == 0 {
 		return CallerInfo{}
 	}
+	frame, _ := runtime.CallersFrames(pcs[:]).Next()
+	c := newCallerInfo(frame.File, frame.Line, frame.Function)
+	c.PC = pcs[0]
+	return c
+}
 
-	// Shorten file path to just the last two segments (package/file.go)
+// newCallerInfo builds a CallerInfo from a raw file path and fully qualified
+// function name, shortening both for display.
+func newCallerInfo(file string, line int, funcName string) CallerInfo {
+	// The package path ends at the first '.' after the last '/':
+	// "github.com/acme/app/payments.(*Ledger).Post".
+	pkg := ""
+	slash := strings.LastIndex(funcName, "/")
+	if dot := strings.Index(funcName[slash+1:], "."); dot >= 0 {
+		pkg = funcName[:slash+1+dot]
+	}
+	// The runtime escapes dots in the last path element:
+	// "gopkg.in/yaml%2ev3.Unmarshal".
+	if strings.Contains(pkg, "%2e") {
+		pkg = strings.ReplaceAll(pkg, "%2e", ".")
+	}
+
+	// Shorten the file path to package/file.go. The package segment comes
+	// from the import path rather than the directory on disk, so output is
+	// the same wherever the module is checked out or cached
+	// (".../loghq@v1.2.0/logger.go" renders as "loghq/logger.go").
 	short := file
+	base := file
 	if idx := strings.LastIndex(file, "/"); idx >= 0 {
+		base = file[idx+1:]
 		if idx2 := strings.LastIndex(file[:idx], "/"); idx2 >= 0 {
 			short = file[idx2+1:]
 		}
 	}
+	if seg := packageSegment(pkg); seg != "" {
+		short = seg + "/" + base
+	}
 
-	fn := runtime.FuncForPC(pc)
-	funcName := ""
-	if fn != nil {
-		funcName = fn.Name()
-		if idx := strings.LastIndex(funcName, "."); idx >= 0 {
-			funcName = funcName[idx+1:]
-		}
+	if idx := strings.LastIndex(funcName, "."); idx >= 0 {
+		funcName = funcName[idx+1:]
 	}
 
 	return CallerInfo{
 		File:     short,
 		Line:     line,
 		Function: funcName,
+		Package:  pkg,
 		defined:  true,
 	}
 }
 
+// packageSegment returns the last element of an import path, skipping a
+// major version suffix ("example.com/app/v2" gives "app") and the _test
+// suffix of an external test package, whose files live in the package's
+// own directory. It returns "" for package main, whose import path says
+// nothing about its location.
+func packageSegment(pkg string) string {
+	if pkg == "" || pkg == "main" {
+		return ""
+	}
+	pkg = strings.TrimSuffix(pkg, "_test")
+	seg := pkg
+	if idx := strings.LastIndex(pkg, "/"); idx >= 0 {
+		seg = pkg[idx+1:]
+		if isMajorVersion(seg) {
+			rest := pkg[:idx]
+			seg = rest[strings.LastIndex(rest, "/")+1:]
+		}
+	}
+	return seg
+}
+
+// isMajorVersion reports whether s is a module major version suffix such
+// as "v2".
+func isMajorVersion(s string) bool {
+	if len(s) < 2 || s[0] != 'v' || s[1] == '0' {
+		return false
+	}
+	for i := 1; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
 // captureStack returns a formatted stack trace.
 func captureStack(skip int) string {
 	const maxDepth = 32
@@ -0,0 +1,322 @@
+package loghq
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config declaratively describes a Logger. It can be decoded from JSON,
+// parsed from simple key=value text, and overridden from LOGHQ_*
+// environment variables, then turned into a Logger with Build.
+type Config struct {
+	// Level is the logger's minimum level. Default: info.
+	Level Level `json:"level"`
+
+	// Caller enables caller capture. Default: true.
+	Caller *bool `json:"caller,omitempty"`
+
+	// CallerSkip adds frames to skip when capturing the caller.
+	CallerSkip int `json:"caller_skip"`
+
+	// StackLevel is the minimum level that captures a stack trace.
+	// Default: error.
+	StackLevel *Level `json:"stack_level,omitempty"`
+
+	// Levels overrides the level of named loggers by longest name prefix,
+	// e.g. {"payments": "debug", "cache": "warn"}. See LevelRules.
+	Levels map[string]Level `json:"levels,omitempty"`
+
+	// Handlers lists the outputs. With none, a console handler on stderr
+	// is used; with several, records fan out through a MultiHandler.
+	Handlers []HandlerConfig `json:"handlers"`
+}
+
+// HandlerConfig describes one handler in a Config.
+type HandlerConfig struct {
+	// Format is "console" (default), "json", or "logfmt".
+	Format string `json:"format"`
+
+	// Level is the handler's own minimum level. Default: trace.
+	Level *Level `json:"level,omitempty"`
+
+	// Output is "stderr" (default), "stdout", or "file".
+	Output string `json:"output"`
+
+	// File configures the rotating writer when Output is "file".
+	File FileConfig `json:"file"`
+
+	// NoColor disables ANSI colors for the console format.
+	NoColor bool `json:"no_color"`
+
+	// TimeLayout overrides the format's default time layout.
+	TimeLayout string `json:"time_layout"`
+}
+
+// LoadConfig reads a configuration file, applies environment overrides,
+// and returns the result. Files ending in .json are decoded as JSON;
+// anything else is parsed as key=value lines (see ParseConfig).
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("loghq: cannot read config %s: %w", path, err)
+	}
+
+	var cfg *Config
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		cfg, err = ParseJSONConfig(data)
+	} else {
+		cfg, err = ParseConfig(data)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.ApplyEnv(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// ParseJSONConfig decodes a JSON configuration. Unknown keys are rejected.
+func ParseJSONConfig(data []byte) (*Config, error) {
+	cfg := &Config{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(cfg); err != nil {
+		return nil, fmt.Errorf("loghq: invalid config: %w", err)
+	}
+	return cfg, nil
+}
+
+// ParseConfig parses key=value lines. Blank lines and lines starting with
+// '#' are ignored. Handler keys configure a single handler:
+//
+//	level=debug
+//	levels=payments=debug,cache=warn
+//	stack_level=error
+//	caller=true
+//	format=json
+//	output=file
+//	file.path=/var/log/app.log
+//	file.max_age=168h
+func ParseConfig(data []byte) (*Config, error) {
+	cfg := &Config{}
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		key, val, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("loghq: config line %d: expected key=value", n)
+		}
+		if err := cfg.set(strings.TrimSpace(key), strings.TrimSpace(val)); err != nil {
+			return nil, fmt.Errorf("loghq: config line %d: %w", n, err)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("loghq: invalid config: %w", err)
+	}
+	return cfg, nil
+}
+
+// configKeys lists the keys accepted by ParseConfig and ApplyEnv.
+var configKeys = []string{
+	"level", "levels", "caller", "caller_skip", "stack_level",
+	"format", "handler_level", "output", "no_color", "time_layout",
+	"file.path", "file.max_size", "file.max_age", "file.max_backups", "file.compress",
+}
+
+// ApplyEnv overrides settings from environment variables named after the
+// ParseConfig keys: LOGHQ_LEVEL, LOGHQ_LEVELS, LOGHQ_FORMAT, LOGHQ_OUTPUT,
+// LOGHQ_FILE_PATH, and so on. LOGHQ_FORMAT applies to every configured
+// handler; other handler keys apply to the first one.
+func (c *Config) ApplyEnv() error {
+	for _, key := range configKeys {
+		name := "LOGHQ_" + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+		val, ok := os.LookupEnv(name)
+		if !ok {
+			continue
+		}
+		if key == "format" && len(c.Handlers) > 1 {
+			for i := range c.Handlers {
+				c.Handlers[i].Format = val
+			}
+			continue
+		}
+		if err := c.set(key, val); err != nil {
+			return fmt.Errorf("loghq: %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// set applies a single key=value setting. Handler keys create the first
+// handler if the config has none.
+func (c *Config) set(key, val string) error {
+	var err error
+	switch key {
+	case "level":
+		return c.Level.UnmarshalText([]byte(val))
+	case "levels":
+		c.Levels, err = ParseLevelRules(val)
+	case "caller":
+		var on bool
+		on, err = strconv.ParseBool(val)
+		c.Caller = &on
+	case "caller_skip":
+		c.CallerSkip, err = strconv.Atoi(val)
+	case "stack_level":
+		var lvl Level
+		err = lvl.UnmarshalText([]byte(val))
+		c.StackLevel = &lvl
+	default:
+		if len(c.Handlers) == 0 {
+			c.Handlers = append(c.Handlers, HandlerConfig{})
+		}
+		return c.Handlers[0].set(key, val)
+	}
+	return err
+}
+
+func (hc *HandlerConfig) set(key, val string) error {
+	var err error
+	switch key {
+	case "format":
+		hc.Format = val
+	case "handler_level":
+		var lvl Level
+		err = lvl.UnmarshalText([]byte(val))
+		hc.Level = &lvl
+	case "output":
+		hc.Output = val
+	case "no_color":
+		hc.NoColor, err = strconv.ParseBool(val)
+	case "time_layout":
+		hc.TimeLayout = val
+	case "file.path":
+		hc.File.Path = val
+	case "file.max_size":
+		hc.File.MaxSize, err = strconv.ParseInt(val, 10, 64)
+	case "file.max_age":
+		hc.File.MaxAge, err = time.ParseDuration(val)
+	case "file.max_backups":
+		hc.File.MaxBackups, err = strconv.Atoi(val)
+	case "file.compress":
+		hc.File.Compress, err = strconv.ParseBool(val)
+	default:
+		return fmt.Errorf("unknown key %q", key)
+	}
+	return err
+}
+
+// Build constructs a Logger from the configuration. If any handler fails
+// to build, files opened for earlier handlers are closed. Build uses c as
+// it is: LOGHQ_* variables are only read by LoadConfig and ApplyEnv, so a
+// Config assembled in code should call ApplyEnv first to honor them.
+func (c *Config) Build() (*Logger, error) {
+	handlers := make([]Handler, 0, len(c.Handlers))
+	var files []*FileWriter
+	for i := range c.Handlers {
+		h, fw, err := c.Handlers[i].build()
+		if err != nil {
+			for _, f := range files {
+				f.Close()
+			}
+			return nil, fmt.Errorf("loghq: handler %d: %w", i, err)
+		}
+		if fw != nil {
+			files = append(files, fw)
+		}
+		handlers = append(handlers, h)
+	}
+
+	var handler Handler
+	switch len(handlers) {
+	case 0:
+		handler = NewConsoleHandler()
+	case 1:
+		handler = handlers[0]
+	default:
+		handler = NewMultiHandler(handlers...)
+	}
+
+	opts := []Option{
+		WithLevel(c.Level),
+		WithHandler(handler),
+		WithCallerSkip(c.CallerSkip),
+	}
+	if c.Caller != nil {
+		opts = append(opts, WithCaller(*c.Caller))
+	}
+	if c.StackLevel != nil {
+		opts = append(opts, WithStackLevel(*c.StackLevel))
+	}
+	if len(c.Levels) > 0 {
+		rules := NewLevelRules()
+		rules.Replace(c.Levels)
+		opts = append(opts, WithLevelRules(rules))
+	}
+	return New(opts...), nil
+}
+
+// build returns the handler and, for file output, the writer it opened.
+func (hc *HandlerConfig) build() (Handler, *FileWriter, error) {
+	var w WriteSyncer
+	var fw *FileWriter
+	switch strings.ToLower(hc.Output) {
+	case "", "stderr":
+		w = Stderr
+	case "stdout":
+		w = Stdout
+	case "file":
+		var err error
+		if fw, err = NewFileWriter(hc.File); err != nil {
+			return nil, nil, err
+		}
+		w = fw
+	default:
+		return nil, nil, fmt.Errorf("unknown output %q", hc.Output)
+	}
+
+	lvl := TraceLevel
+	if hc.Level != nil {
+		lvl = *hc.Level
+	}
+
+	switch strings.ToLower(hc.Format) {
+	case "", "console":
+		opts := []ConsoleOption{WithConsoleWriter(w), WithConsoleLevel(lvl)}
+		if hc.NoColor {
+			opts = append(opts, WithConsoleNoColor())
+		}
+		if hc.TimeLayout != "" {
+			opts = append(opts, WithConsoleTimeLayout(hc.TimeLayout))
+		}
+		return NewConsoleHandler(opts...), fw, nil
+	case "json":
+		opts := []JSONOption{WithJSONLevel(lvl)}
+		if hc.TimeLayout != "" {
+			opts = append(opts, WithJSONTimeLayout(hc.TimeLayout))
+		}
+		return NewJSONHandler(w, opts...), fw, nil
+	case "logfmt":
+		opts := []LogfmtOption{WithLogfmtLevel(lvl)}
+		if hc.TimeLayout != "" {
+			opts = append(opts, WithLogfmtTimeLayout(hc.TimeLayout))
+		}
+		return NewLogfmtHandler(w, opts...), fw, nil
+	default:
+		if fw != nil {
+			fw.Close()
+		}
+		return nil, nil, fmt.Errorf("unknown format %q", hc.Format)
+	}
+}
@@ -4,6 +4,25 @@ import "context"
 
 type ctxFieldsKey struct{}
 
+type ctxLoggerKey struct{}
+
+// IntoContext returns a context carrying l, so a request-scoped logger can
+// travel through call stacks that only pass a context.
+func IntoContext(ctx context.Context, l *Logger) context.Context {
+	return context.WithValue(ctx, ctxLoggerKey{}, l)
+}
+
+// FromContext returns the logger stored by IntoContext, or the default
+// logger if ctx carries none. It never returns nil.
+func FromContext(ctx context.Context) *Logger {
+	if ctx != nil {
+		if l, ok := ctx.Value(ctxLoggerKey{}).(*Logger); ok && l != nil {
+			return l
+		}
+	}
+	return defaultLogger.Load()
+}
+
 // ContextWithFields attaches logging fields to a context.
 // These fields will be automatically included in log calls made with WithContext.
 func ContextWithFields(ctx context.Context, fields ...Field) context.Context {
@@ -24,3 +43,8 @@ func fieldsFromContext(ctx context.Context) []Field {
 	}
 	return nil
 }
+
+// ContextExtractor derives logging fields from a context. Extractors
+// registered with WithContextExtractor run on every record logged through
+// a context-bound logger, after the fields from ContextWithFields.
+type ContextExtractor func(ctx context.Context) []Field
@@ -17,10 +17,17 @@ type Encoder interface {
 type FieldEncoder interface {
 	EncodeString(key, val string)
 	EncodeInt64(key string, val int64)
+	EncodeUint64(key string, val uint64)
 	EncodeFloat64(key string, val float64)
 	EncodeBool(key string, val bool)
 	EncodeDuration(key string, val time.Duration)
 	EncodeTime(key string, val time.Time)
+	EncodeBytes(key string, val []byte)
+	EncodeComplex128(key string, val complex128)
 	EncodeError(key string, msg string)
 	EncodeAny(key string, val interface{})
+	EncodeGroup(key string, fields []Field)
+	OpenNamespace(key string)
+	EncodeObject(key string, obj ObjectMarshaler)
+	EncodeArray(key string, arr ArrayMarshaler)
 }
@@ -20,9 +20,9 @@ const (
 )
 
 // Level rendering metadata.
-var levelIcons = [7]string{"◦", "◇", "●", "✓", "▲", "✗", "✗"}
-var levelColors = [7]string{colorGray, colorCyan, colorBlue, colorGreen, colorYellow, colorRed, colorBoldRed}
-var levelPadded = [7]string{"TRACE ", "DEBUG ", "INFO  ", "OK    ", "WARN  ", "ERROR ", "FATAL "}
+var levelIcons = [8]string{"◦", "◇", "●", "✓", "▲", "✗", "✗", "✗"}
+var levelColors = [8]string{colorGray, colorCyan, colorBlue, colorGreen, colorYellow, colorRed, colorBoldRed, colorBoldRed}
+var levelPadded = [8]string{"TRACE ", "DEBUG ", "INFO  ", "OK    ", "WARN  ", "ERROR ", "FATAL ", "PANIC "}
 
 const defaultTimeLayout = "2006-01-02 15:04:05"
 
@@ -80,17 +80,35 @@ func (e *ConsoleEncoder) Encode(buf *Buffer, rec *Record) {
 		buf.AppendString(colorReset)
 	}
 
+	// Logger name
+	if rec.Name != "" {
+		if !e.NoColor {
+			buf.AppendString(colorGray)
+		}
+		buf.AppendByte('[')
+		buf.AppendString(rec.Name)
+		buf.AppendString("] ")
+		if !e.NoColor {
+			buf.AppendString(colorReset)
+		}
+	}
+
 	// Message
 	buf.AppendString(rec.Message)
 
-	// Fields — direct encoding avoids interface escape to heap
+	// Fields — direct encoding avoids interface escape to heap.
+	// Namespaces and groups flatten into dotted key prefixes.
 	if nf := rec.NumFields(); nf > 0 {
-		buf.AppendString("  ")
+		buf.AppendByte(' ')
+		var pathBuf [4]string
+		path := pathBuf[:0]
 		for i := 0; i < nf; i++ {
-			if i > 0 {
-				buf.AppendByte(' ')
+			f := rec.FieldAt(i)
+			if f.Type == FieldNamespace {
+				path = append(path, f.Key)
+				continue
 			}
-			e.encodeField(buf, rec.FieldAt(i))
+			e.encodeField(buf, path, f)
 		}
 	}
 
@@ -119,21 +137,42 @@ func (e *ConsoleEncoder) Encode(buf *Buffer, rec *Record) {
 	}
 }
 
-func (e *ConsoleEncoder) appendFieldKey(buf *Buffer, key string) {
+func (e *ConsoleEncoder) appendKey(buf *Buffer, path []string, key string) {
 	if e.NoColor {
-		buf.AppendString(key)
+		appendDottedKey(buf, path, key)
 		buf.AppendByte('=')
 	} else {
 		buf.AppendString(colorDim)
-		buf.AppendString(key)
+		appendDottedKey(buf, path, key)
 		buf.AppendString("=" + colorReset)
 	}
 }
 
 // encodeField encodes a single field directly without going through the
-// FieldEncoder interface, avoiding heap escape.
-func (e *ConsoleEncoder) encodeField(buf *Buffer, f *Field) {
-	e.appendFieldKey(buf, f.Key)
+// FieldEncoder interface, avoiding heap escape. Each field writes its own
+// leading space; path holds the enclosing group names. Structured errors
+// flatten like objects.
+func (e *ConsoleEncoder) encodeField(buf *Buffer, path []string, f *Field) {
+	if f.Type == FieldLazy {
+		r := f.resolveLazy()
+		f = &r
+	}
+	if f.Type == FieldError {
+		if obj, ok := f.asErrorObject(); ok {
+			f = &obj
+		}
+	}
+	if f.Type == FieldGroup || f.Type == FieldObject {
+		encodeTextNested(textFormat{console: e}, buf, path, f)
+		return
+	}
+	buf.AppendByte(' ')
+	e.appendKey(buf, path, f.Key)
+	e.encodeValue(buf, f)
+}
+
+// encodeValue writes the display value of f without its key.
+func (e *ConsoleEncoder) encodeValue(buf *Buffer, f *Field) {
 	switch f.Type {
 	case FieldString:
 		buf.AppendString(f.Str)
@@ -153,5 +192,11 @@ func (e *ConsoleEncoder) encodeField(buf *Buffer, f *Field) {
 		buf.AppendString(f.Str)
 	case FieldAny:
 		buf.AppendString(formatAny(f.Iface))
+	case FieldStringer:
+		buf.AppendString(stringerValue(f.Iface))
+	case FieldUint64, FieldBytes, FieldComplex:
+		appendTextScalar(buf, f)
+	case FieldGroup, FieldObject, FieldArray:
+		encodeTextComposite(textFormat{console: e}, buf, f)
 	}
 }
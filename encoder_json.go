@@ -1,7 +1,17 @@
 package loghq
 
 import (
+	"bytes"
+	"encoding"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
 	"math"
+	"reflect"
+	"slices"
+	"strconv"
+	"sync"
 	"time"
 )
 
@@ -11,9 +21,13 @@ type JSONEncoder struct {
 	TimeKey    string
 	LevelKey   string
 	MessageKey string
+	NameKey    string
 	CallerKey  string
 	StackKey   string
 	TimeLayout string
+
+	// HexBytes encodes Bytes fields as hex instead of base64.
+	HexBytes bool
 }
 
 func (e *JSONEncoder) key(custom, fallback string) string {
@@ -48,6 +62,14 @@ func (e *JSONEncoder) Encode(buf *Buffer, rec *Record) {
 	buf.AppendString(rec.Level.String())
 	buf.AppendByte('"')
 
+	// Logger name
+	if rec.Name != "" {
+		buf.AppendString(`,"`)
+		buf.AppendString(e.key(e.NameKey, "logger"))
+		buf.AppendString(`":`)
+		appendJSONString(buf, rec.Name)
+	}
+
 	// Message
 	buf.AppendString(`,"`)
 	buf.AppendString(e.key(e.MessageKey, "msg"))
@@ -63,11 +85,27 @@ func (e *JSONEncoder) Encode(buf *Buffer, rec *Record) {
 		buf.AppendByte('"')
 	}
 
-	// Fields — direct encoding avoids interface escape to heap
+	// Fields — direct encoding avoids interface escape to heap.
+	// A namespace opens an object that stays open until the last field.
+	open := 0
+	needComma := true
 	for i, nf := 0, rec.NumFields(); i < nf; i++ {
-		buf.AppendByte(',')
+		if needComma {
+			buf.AppendByte(',')
+		}
 		f := rec.FieldAt(i)
+		if f.Type == FieldNamespace {
+			appendJSONKey(buf, f.Key)
+			buf.AppendByte('{')
+			open++
+			needComma = false
+			continue
+		}
 		e.encodeField(buf, f)
+		needComma = true
+	}
+	for ; open > 0; open-- {
+		buf.AppendByte('}')
 	}
 
 	// Stack
@@ -84,18 +122,19 @@ func (e *JSONEncoder) Encode(buf *Buffer, rec *Record) {
 // encodeField encodes a single field directly without going through the
 // FieldEncoder interface, avoiding heap escape of the receiver.
 func (e *JSONEncoder) encodeField(buf *Buffer, f *Field) {
-	// key
-	buf.AppendByte('"')
-	buf.AppendString(f.Key)
-	buf.AppendString(`":`)
-	// value
+	appendJSONKey(buf, f.Key)
+	e.encodeValue(buf, f)
+}
+
+// encodeValue writes the JSON value of f without its key.
+func (e *JSONEncoder) encodeValue(buf *Buffer, f *Field) {
 	switch f.Type {
 	case FieldString:
 		appendJSONString(buf, f.Str)
 	case FieldInt64:
 		buf.AppendInt(f.Ival)
 	case FieldFloat64:
-		buf.AppendFloat(math.Float64frombits(uint64(f.Ival)))
+		appendJSONFloat(buf, math.Float64frombits(uint64(f.Ival)))
 	case FieldBool:
 		buf.AppendBool(f.Ival == 1)
 	case FieldDuration:
@@ -107,14 +146,240 @@ func (e *JSONEncoder) encodeField(buf *Buffer, f *Field) {
 			buf.AppendByte('"')
 		}
 	case FieldError:
-		appendJSONString(buf, f.Str)
+		if obj, ok := f.asErrorObject(); ok {
+			e.encodeValue(buf, &obj)
+		} else {
+			appendJSONString(buf, f.Str)
+		}
 	case FieldAny:
-		appendJSONString(buf, formatAny(f.Iface))
+		appendJSONAny(buf, f.Iface)
+	case FieldUint64:
+		buf.AppendUint(uint64(f.Ival))
+	case FieldBytes:
+		b, _ := f.Iface.([]byte)
+		buf.AppendByte('"')
+		if e.HexBytes {
+			buf.B = hex.AppendEncode(buf.B, b)
+		} else {
+			buf.B = base64.StdEncoding.AppendEncode(buf.B, b)
+		}
+		buf.AppendByte('"')
+	case FieldComplex:
+		c, _ := f.Iface.(complex128)
+		buf.AppendByte('"')
+		buf.AppendComplex(c)
+		buf.AppendByte('"')
+	case FieldStringer:
+		appendJSONString(buf, stringerValue(f.Iface))
+	case FieldGroup:
+		fields, _ := f.Iface.([]Field)
+		oe := e.objectEncoder(buf)
+		for i := range fields {
+			oe.add(&fields[i])
+		}
+		oe.close()
+	case FieldObject:
+		m, _ := f.Iface.(ObjectMarshaler)
+		oe := e.objectEncoder(buf)
+		if m != nil {
+			if err := m.MarshalLogObject(oe); err != nil {
+				oe.EncodeError("error", err.Error())
+			}
+		}
+		oe.close()
+	case FieldArray:
+		m, _ := f.Iface.(ArrayMarshaler)
+		ae := jsonArrayEncoderPool.Get().(*jsonArrayEncoder)
+		ae.enc, ae.buf, ae.n = e, buf, 0
+		buf.AppendByte('[')
+		if m != nil {
+			if err := m.MarshalLogArray(ae); err != nil {
+				ae.AppendString(err.Error())
+			}
+		}
+		buf.AppendByte(']')
+		ae.enc, ae.buf = nil, nil
+		jsonArrayEncoderPool.Put(ae)
+	}
+}
+
+// objectEncoder opens a JSON object on buf and returns a pooled encoder for
+// its members. The caller must call close.
+func (e *JSONEncoder) objectEncoder(buf *Buffer) *jsonObjectEncoder {
+	oe := jsonObjectEncoderPool.Get().(*jsonObjectEncoder)
+	oe.enc, oe.buf, oe.needComma, oe.open = e, buf, false, 1
+	buf.AppendByte('{')
+	return oe
+}
+
+// --- Object and array encoders ---
+
+var (
+	jsonObjectEncoderPool = sync.Pool{New: func() interface{} { return &jsonObjectEncoder{} }}
+	jsonArrayEncoderPool  = sync.Pool{New: func() interface{} { return &jsonArrayEncoder{} }}
+)
+
+// jsonObjectEncoder implements FieldEncoder for the members of a JSON
+// object. Instances are pooled so ObjectMarshaler calls don't allocate.
+type jsonObjectEncoder struct {
+	enc       *JSONEncoder
+	buf       *Buffer
+	needComma bool
+	open      int
+}
+
+func (o *jsonObjectEncoder) add(f *Field) {
+	if o.needComma {
+		o.buf.AppendByte(',')
+	}
+	if f.Type == FieldLazy {
+		r := f.resolveLazy()
+		f = &r
+	}
+	if f.Type == FieldNamespace {
+		appendJSONKey(o.buf, f.Key)
+		o.buf.AppendByte('{')
+		o.open++
+		o.needComma = false
+		return
+	}
+	o.enc.encodeField(o.buf, f)
+	o.needComma = true
+}
+
+// close ends the object and any namespaces opened inside it, then returns
+// the encoder to its pool.
+func (o *jsonObjectEncoder) close() {
+	for ; o.open > 0; o.open-- {
+		o.buf.AppendByte('}')
+	}
+	o.enc, o.buf = nil, nil
+	jsonObjectEncoderPool.Put(o)
+}
+
+func (o *jsonObjectEncoder) EncodeString(key, val string) {
+	o.add(&Field{Key: key, Type: FieldString, Str: val})
+}
+
+func (o *jsonObjectEncoder) EncodeInt64(key string, val int64) {
+	o.add(&Field{Key: key, Type: FieldInt64, Ival: val})
+}
+
+func (o *jsonObjectEncoder) EncodeUint64(key string, val uint64) {
+	o.add(&Field{Key: key, Type: FieldUint64, Ival: int64(val)})
+}
+
+func (o *jsonObjectEncoder) EncodeBytes(key string, val []byte) {
+	o.add(&Field{Key: key, Type: FieldBytes, Iface: val})
+}
+
+func (o *jsonObjectEncoder) EncodeComplex128(key string, val complex128) {
+	o.add(&Field{Key: key, Type: FieldComplex, Iface: val})
+}
+
+func (o *jsonObjectEncoder) EncodeFloat64(key string, val float64) {
+	o.add(&Field{Key: key, Type: FieldFloat64, Ival: int64(math.Float64bits(val))})
+}
+
+func (o *jsonObjectEncoder) EncodeBool(key string, val bool) {
+	o.add(&Field{Key: key, Type: FieldBool, Ival: boolToInt64(val)})
+}
+
+func (o *jsonObjectEncoder) EncodeDuration(key string, val time.Duration) {
+	o.add(&Field{Key: key, Type: FieldDuration, Ival: int64(val)})
+}
+
+func (o *jsonObjectEncoder) EncodeTime(key string, val time.Time) {
+	o.add(&Field{Key: key, Type: FieldTime, Iface: val})
+}
+
+func (o *jsonObjectEncoder) EncodeError(key string, msg string) {
+	o.add(&Field{Key: key, Type: FieldError, Str: msg})
+}
+
+func (o *jsonObjectEncoder) EncodeAny(key string, val interface{}) {
+	o.add(&Field{Key: key, Type: FieldAny, Iface: val})
+}
+
+func (o *jsonObjectEncoder) EncodeGroup(key string, fields []Field) {
+	o.add(&Field{Key: key, Type: FieldGroup, Iface: fields})
+}
+
+func (o *jsonObjectEncoder) OpenNamespace(key string) {
+	o.add(&Field{Key: key, Type: FieldNamespace})
+}
+
+func (o *jsonObjectEncoder) EncodeObject(key string, obj ObjectMarshaler) {
+	o.add(&Field{Key: key, Type: FieldObject, Iface: obj})
+}
+
+func (o *jsonObjectEncoder) EncodeArray(key string, arr ArrayMarshaler) {
+	o.add(&Field{Key: key, Type: FieldArray, Iface: arr})
+}
+
+// jsonArrayEncoder implements ArrayEncoder for the elements of a JSON array.
+type jsonArrayEncoder struct {
+	enc *JSONEncoder
+	buf *Buffer
+	n   int
+}
+
+func (a *jsonArrayEncoder) add(f *Field) {
+	if a.n > 0 {
+		a.buf.AppendByte(',')
 	}
+	a.n++
+	a.enc.encodeValue(a.buf, f)
+}
+
+func (a *jsonArrayEncoder) AppendString(val string) {
+	a.add(&Field{Type: FieldString, Str: val})
+}
+
+func (a *jsonArrayEncoder) AppendInt64(val int64) {
+	a.add(&Field{Type: FieldInt64, Ival: val})
+}
+
+func (a *jsonArrayEncoder) AppendUint64(val uint64) {
+	a.add(&Field{Type: FieldUint64, Ival: int64(val)})
+}
+
+func (a *jsonArrayEncoder) AppendFloat64(val float64) {
+	a.add(&Field{Type: FieldFloat64, Ival: int64(math.Float64bits(val))})
+}
+
+func (a *jsonArrayEncoder) AppendBool(val bool) {
+	a.add(&Field{Type: FieldBool, Ival: boolToInt64(val)})
+}
+
+func (a *jsonArrayEncoder) AppendDuration(val time.Duration) {
+	a.add(&Field{Type: FieldDuration, Ival: int64(val)})
+}
+
+func (a *jsonArrayEncoder) AppendTime(val time.Time) {
+	a.add(&Field{Type: FieldTime, Iface: val})
+}
+
+func (a *jsonArrayEncoder) AppendAny(val interface{}) {
+	a.add(&Field{Type: FieldAny, Iface: val})
+}
+
+func (a *jsonArrayEncoder) AppendObject(obj ObjectMarshaler) {
+	a.add(&Field{Type: FieldObject, Iface: obj})
+}
+
+func (a *jsonArrayEncoder) AppendArray(arr ArrayMarshaler) {
+	a.add(&Field{Type: FieldArray, Iface: arr})
 }
 
 // --- JSON helpers ---
 
+func appendJSONKey(buf *Buffer, key string) {
+	buf.AppendByte('"')
+	buf.AppendString(key)
+	buf.AppendString(`":`)
+}
+
 func appendJSONString(buf *Buffer, s string) {
 	buf.AppendByte('"')
 	for i := 0; i < len(s); i++ {
@@ -143,6 +408,190 @@ func appendJSONString(buf *Buffer, s string) {
 	buf.AppendByte('"')
 }
 
+// appendJSONAny encodes an arbitrary value as native JSON. Marshalers are
+// honored first, common slice and map types are encoded directly, and other
+// composite values (structs, arrays, maps) fall back to encoding/json.
+// Anything else is written as its string form.
+func appendJSONAny(buf *Buffer, v interface{}) {
+	switch v := v.(type) {
+	case nil:
+		buf.AppendString("null")
+	case json.Marshaler:
+		appendJSONMarshaler(buf, v)
+	case encoding.TextMarshaler:
+		if isNilPointer(v) {
+			buf.AppendString("null")
+			return
+		}
+		b, err := v.MarshalText()
+		if err != nil {
+			appendJSONString(buf, formatAny(v))
+			return
+		}
+		appendJSONString(buf, string(b))
+	case error:
+		appendJSONString(buf, v.Error())
+	case string:
+		appendJSONString(buf, v)
+	case bool:
+		buf.AppendBool(v)
+	case int:
+		buf.AppendInt(int64(v))
+	case int64:
+		buf.AppendInt(v)
+	case int32:
+		buf.AppendInt(int64(v))
+	case uint:
+		buf.AppendUint(uint64(v))
+	case uint64:
+		buf.AppendUint(v)
+	case uint32:
+		buf.AppendUint(uint64(v))
+	case float64:
+		appendJSONFloat(buf, v)
+	case float32:
+		appendJSONFloat(buf, float64(v))
+	case time.Duration:
+		appendJSONString(buf, v.String())
+	case []string:
+		buf.AppendByte('[')
+		for i, s := range v {
+			if i > 0 {
+				buf.AppendByte(',')
+			}
+			appendJSONString(buf, s)
+		}
+		buf.AppendByte(']')
+	case []int:
+		buf.AppendByte('[')
+		for i, n := range v {
+			if i > 0 {
+				buf.AppendByte(',')
+			}
+			buf.AppendInt(int64(n))
+		}
+		buf.AppendByte(']')
+	case []int64:
+		buf.AppendByte('[')
+		for i, n := range v {
+			if i > 0 {
+				buf.AppendByte(',')
+			}
+			buf.AppendInt(n)
+		}
+		buf.AppendByte(']')
+	case []float64:
+		buf.AppendByte('[')
+		for i, n := range v {
+			if i > 0 {
+				buf.AppendByte(',')
+			}
+			appendJSONFloat(buf, n)
+		}
+		buf.AppendByte(']')
+	case []interface{}:
+		buf.AppendByte('[')
+		for i, e := range v {
+			if i > 0 {
+				buf.AppendByte(',')
+			}
+			appendJSONAny(buf, e)
+		}
+		buf.AppendByte(']')
+	case map[string]string:
+		buf.AppendByte('{')
+		for i, k := range sortedKeys(v) {
+			if i > 0 {
+				buf.AppendByte(',')
+			}
+			appendJSONString(buf, k)
+			buf.AppendByte(':')
+			appendJSONString(buf, v[k])
+		}
+		buf.AppendByte('}')
+	case map[string]interface{}:
+		appendJSONMap(buf, v)
+	case Fields:
+		appendJSONMap(buf, v)
+	default:
+		rv := reflect.ValueOf(v)
+		for rv.Kind() == reflect.Pointer && !rv.IsNil() {
+			rv = rv.Elem()
+		}
+		switch rv.Kind() {
+		case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+			if b, err := json.Marshal(v); err == nil {
+				buf.AppendBytes(b)
+				return
+			}
+		}
+		appendJSONString(buf, formatAny(v))
+	}
+}
+
+// appendJSONMarshaler writes v's MarshalJSON output compacted onto one line.
+// A nil pointer writes null, and a panic is reported in place of the value
+// the way stringerValue reports one from String.
+func appendJSONMarshaler(buf *Buffer, v json.Marshaler) {
+	if isNilPointer(v) {
+		buf.AppendString("null")
+		return
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			appendJSONString(buf, fmt.Sprintf("<PANIC=%v>", r))
+		}
+	}()
+	b, err := v.MarshalJSON()
+	var out bytes.Buffer
+	if err == nil {
+		err = json.Compact(&out, b)
+	}
+	if err != nil {
+		appendJSONString(buf, formatAny(v))
+		return
+	}
+	buf.AppendBytes(out.Bytes())
+}
+
+func isNilPointer(v interface{}) bool {
+	rv := reflect.ValueOf(v)
+	return rv.Kind() == reflect.Pointer && rv.IsNil()
+}
+
+func appendJSONMap(buf *Buffer, m map[string]interface{}) {
+	buf.AppendByte('{')
+	for i, k := range sortedKeys(m) {
+		if i > 0 {
+			buf.AppendByte(',')
+		}
+		appendJSONString(buf, k)
+		buf.AppendByte(':')
+		appendJSONAny(buf, m[k])
+	}
+	buf.AppendByte('}')
+}
+
+// appendJSONFloat writes f as a number, quoting NaN and ±Inf, which JSON
+// cannot represent.
+func appendJSONFloat(buf *Buffer, f float64) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		appendJSONString(buf, strconv.FormatFloat(f, 'f', -1, 64))
+		return
+	}
+	buf.AppendFloat(f)
+}
+
+// sortedKeys returns the keys of m in order, so map output is deterministic.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
 func hexChar(c byte) byte {
 	if c < 10 {
 		return '0' + c
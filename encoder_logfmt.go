@@ -2,7 +2,6 @@ package loghq
 
 import (
 	"math"
-	"strings"
 	"time"
 )
 
@@ -27,7 +26,13 @@ func (e *LogfmtEncoder) Encode(buf *Buffer, rec *Record) {
 
 	// level=
 	buf.AppendString(" level=")
-	buf.AppendString(strings.ToLower(rec.Level.String()))
+	buf.AppendString(rec.Level.lowerString())
+
+	// logger=
+	if rec.Name != "" {
+		buf.AppendString(" logger=")
+		appendLogfmtValue(buf, rec.Name)
+	}
 
 	// msg=
 	buf.AppendString(" msg=")
@@ -39,20 +44,52 @@ func (e *LogfmtEncoder) Encode(buf *Buffer, rec *Record) {
 		buf.AppendString(rec.Caller.String())
 	}
 
-	// Fields — direct encoding avoids interface escape to heap
+	// Fields — direct encoding avoids interface escape to heap.
+	// Namespaces and groups flatten into dotted key prefixes.
+	var pathBuf [4]string
+	path := pathBuf[:0]
 	for i, nf := 0, rec.NumFields(); i < nf; i++ {
-		buf.AppendByte(' ')
-		e.encodeField(buf, rec.FieldAt(i))
+		f := rec.FieldAt(i)
+		if f.Type == FieldNamespace {
+			path = append(path, f.Key)
+			continue
+		}
+		e.encodeField(buf, path, f)
 	}
 
 	buf.AppendByte('\n')
 }
 
 // encodeField encodes a single field directly without going through the
-// FieldEncoder interface, avoiding heap escape.
-func (e *LogfmtEncoder) encodeField(buf *Buffer, f *Field) {
-	buf.AppendString(f.Key)
+// FieldEncoder interface, avoiding heap escape. Each field writes its own
+// leading space; path holds the enclosing group names. Structured errors
+// flatten like objects.
+func (e *LogfmtEncoder) encodeField(buf *Buffer, path []string, f *Field) {
+	if f.Type == FieldLazy {
+		r := f.resolveLazy()
+		f = &r
+	}
+	if f.Type == FieldError {
+		if obj, ok := f.asErrorObject(); ok {
+			f = &obj
+		}
+	}
+	if f.Type == FieldGroup || f.Type == FieldObject {
+		encodeTextNested(textFormat{logfmt: e}, buf, path, f)
+		return
+	}
+	buf.AppendByte(' ')
+	e.appendKey(buf, path, f.Key)
+	e.encodeValue(buf, f)
+}
+
+func (e *LogfmtEncoder) appendKey(buf *Buffer, path []string, key string) {
+	appendDottedKey(buf, path, key)
 	buf.AppendByte('=')
+}
+
+// encodeValue writes the logfmt value of f without its key.
+func (e *LogfmtEncoder) encodeValue(buf *Buffer, f *Field) {
 	switch f.Type {
 	case FieldString:
 		appendLogfmtValue(buf, f.Str)
@@ -72,11 +109,26 @@ func (e *LogfmtEncoder) encodeField(buf *Buffer, f *Field) {
 		appendLogfmtValue(buf, f.Str)
 	case FieldAny:
 		appendLogfmtValue(buf, formatAny(f.Iface))
+	case FieldStringer:
+		appendLogfmtValue(buf, stringerValue(f.Iface))
+	case FieldUint64, FieldBytes, FieldComplex:
+		appendTextScalar(buf, f)
+	case FieldGroup, FieldObject, FieldArray:
+		encodeTextComposite(textFormat{logfmt: e}, buf, f)
 	}
 }
 
 // --- Logfmt helpers ---
 
+// appendDottedKey writes key prefixed by each group name in path.
+func appendDottedKey(buf *Buffer, path []string, key string) {
+	for _, p := range path {
+		buf.AppendString(p)
+		buf.AppendByte('.')
+	}
+	buf.AppendString(key)
+}
+
 func appendLogfmtValue(buf *Buffer, s string) {
 	if s == "" {
 		buf.AppendString(`""`)
@@ -91,13 +143,27 @@ func appendLogfmtValue(buf *Buffer, s string) {
 		}
 	}
 	if needsQuote {
+		// Control characters are escaped so a record stays on one line.
+		const hex = "0123456789abcdef"
 		buf.AppendByte('"')
 		for i := 0; i < len(s); i++ {
-			c := s[i]
-			if c == '"' || c == '\\' {
+			switch c := s[i]; {
+			case c == '"' || c == '\\':
 				buf.AppendByte('\\')
+				buf.AppendByte(c)
+			case c == '\n':
+				buf.AppendString(`\n`)
+			case c == '\r':
+				buf.AppendString(`\r`)
+			case c == '\t':
+				buf.AppendString(`\t`)
+			case c < 0x20:
+				buf.AppendString(`\u00`)
+				buf.AppendByte(hex[c>>4])
+				buf.AppendByte(hex[c&0xf])
+			default:
+				buf.AppendByte(c)
 			}
-			buf.AppendByte(c)
 		}
 		buf.AppendByte('"')
 	} else {
@@ -0,0 +1,367 @@
+package loghq
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// SyslogFormat selects the syslog message format.
+type SyslogFormat uint8
+
+const (
+	// SyslogRFC5424 is the structured format: fields become structured data.
+	SyslogRFC5424 SyslogFormat = iota
+	// SyslogRFC3164 is the legacy BSD format: fields follow the message as
+	// logfmt pairs.
+	SyslogRFC3164
+)
+
+// SyslogFraming selects how messages are delimited on the wire.
+type SyslogFraming uint8
+
+const (
+	// SyslogFramingLF terminates each message with a newline
+	// (non-transparent framing, RFC 6587 section 3.4.2).
+	SyslogFramingLF SyslogFraming = iota
+	// SyslogFramingOctetCounting prefixes each message with its length
+	// (RFC 6587 section 3.4.1), the safe choice for TCP.
+	SyslogFramingOctetCounting
+	// SyslogFramingNone writes bare messages, one per datagram.
+	SyslogFramingNone
+)
+
+// SyslogFacility is the syslog facility code.
+type SyslogFacility uint8
+
+const (
+	FacilityKern SyslogFacility = iota
+	FacilityUser
+	FacilityMail
+	FacilityDaemon
+	FacilityAuth
+	FacilitySyslog
+	FacilityLPR
+	FacilityNews
+	FacilityUUCP
+	FacilityCron
+	FacilityAuthPriv
+	FacilityFTP
+	FacilityLocal0 SyslogFacility = iota + 4
+	FacilityLocal1
+	FacilityLocal2
+	FacilityLocal3
+	FacilityLocal4
+	FacilityLocal5
+	FacilityLocal6
+	FacilityLocal7
+)
+
+// SyslogSeverity maps a Level to a syslog severity: Trace and Debug are
+// debug (7), Info is informational (6), Success is notice (5), Warn is
+// warning (4), Error is err (3), and Panic and Fatal are crit (2).
+func SyslogSeverity(l Level) int {
+	switch {
+	case l <= DebugLevel:
+		return 7
+	case l == InfoLevel:
+		return 6
+	case l == SuccessLevel:
+		return 5
+	case l == WarnLevel:
+		return 4
+	case l == ErrorLevel:
+		return 3
+	default:
+		return 2
+	}
+}
+
+const defaultSyslogSDID = "fields@32473"
+
+// SyslogEncoder writes records as syslog messages.
+// Thread-safe: no mutable state stored between Encode calls.
+type SyslogEncoder struct {
+	Format   SyslogFormat
+	Framing  SyslogFraming
+	Facility SyslogFacility
+
+	// Hostname, AppName, and ProcID fill the header. Empty values are
+	// sent as "-" (RFC 5424) or omitted (RFC 3164).
+	Hostname string
+	AppName  string
+	ProcID   string
+
+	// SDID is the structured data element ID for fields. Default:
+	// "fields@32473".
+	SDID string
+}
+
+// NewSyslogEncoder returns an RFC 5424 encoder with the header filled in
+// from the host name, program name, and process ID.
+func NewSyslogEncoder() *SyslogEncoder {
+	host, _ := os.Hostname()
+	return &SyslogEncoder{
+		Facility: FacilityUser,
+		Hostname: host,
+		AppName:  filepath.Base(os.Args[0]),
+		ProcID:   strconv.Itoa(os.Getpid()),
+	}
+}
+
+// Encode writes a framed syslog message. Thread-safe.
+func (e *SyslogEncoder) Encode(buf *Buffer, rec *Record) {
+	if e.Framing != SyslogFramingOctetCounting {
+		e.encodeMessage(buf, rec)
+		if e.Framing == SyslogFramingLF {
+			buf.AppendByte('\n')
+		}
+		return
+	}
+
+	msg := getBuffer()
+	e.encodeMessage(msg, rec)
+	buf.AppendInt(int64(msg.Len()))
+	buf.AppendByte(' ')
+	buf.AppendBytes(msg.Bytes())
+	putBuffer(msg)
+}
+
+func (e *SyslogEncoder) encodeMessage(buf *Buffer, rec *Record) {
+	buf.AppendByte('<')
+	buf.AppendInt(int64(e.Facility)*8 + int64(SyslogSeverity(rec.Level)))
+	buf.AppendByte('>')
+	if e.Format == SyslogRFC3164 {
+		e.encode3164(buf, rec)
+		return
+	}
+	e.encode5424(buf, rec)
+}
+
+// encode5424 writes: 1 TIMESTAMP HOSTNAME APP-NAME PROCID MSGID [SD] MSG
+func (e *SyslogEncoder) encode5424(buf *Buffer, rec *Record) {
+	buf.AppendString("1 ")
+	buf.AppendTime(rec.Time, "2006-01-02T15:04:05.000000Z07:00")
+	buf.AppendByte(' ')
+	appendSyslogHeader(buf, e.Hostname, 255)
+	buf.AppendByte(' ')
+	appendSyslogHeader(buf, e.AppName, 48)
+	buf.AppendByte(' ')
+	appendSyslogHeader(buf, e.ProcID, 128)
+	buf.AppendByte(' ')
+	appendSyslogHeader(buf, rec.Name, 32)
+	buf.AppendByte(' ')
+
+	if rec.NumFields() == 0 && !rec.Caller.Defined() && rec.Stack == "" {
+		buf.AppendByte('-')
+	} else {
+		buf.AppendByte('[')
+		if e.SDID != "" {
+			buf.AppendString(e.SDID)
+		} else {
+			buf.AppendString(defaultSyslogSDID)
+		}
+		if rec.Caller.Defined() {
+			buf.AppendString(` caller="`)
+			appendSDValue(buf, rec.Caller.String())
+			buf.AppendByte('"')
+		}
+		var pathBuf [4]string
+		path := pathBuf[:0]
+		for i, nf := 0, rec.NumFields(); i < nf; i++ {
+			f := rec.FieldAt(i)
+			if f.Type == FieldNamespace {
+				path = append(path, f.Key)
+				continue
+			}
+			appendSDParam(buf, path, f)
+		}
+		if rec.Stack != "" {
+			buf.AppendString(` stack="`)
+			appendSDValue(buf, rec.Stack)
+			buf.AppendByte('"')
+		}
+		buf.AppendByte(']')
+	}
+
+	if rec.Message != "" {
+		buf.AppendByte(' ')
+		appendSyslogMsg(buf, rec.Message)
+	}
+}
+
+// encode3164 writes: Mmm dd hh:mm:ss HOSTNAME TAG[PID]: MSG key=value...
+func (e *SyslogEncoder) encode3164(buf *Buffer, rec *Record) {
+	buf.AppendTime(rec.Time, time.Stamp)
+	if e.Hostname != "" {
+		buf.AppendByte(' ')
+		appendSyslogHeader(buf, e.Hostname, 255)
+	}
+	buf.AppendByte(' ')
+	if e.AppName != "" {
+		appendSyslogHeader(buf, e.AppName, 32)
+	} else {
+		buf.AppendString("loghq")
+	}
+	if e.ProcID != "" {
+		buf.AppendByte('[')
+		appendSyslogHeader(buf, e.ProcID, 128)
+		buf.AppendByte(']')
+	}
+	buf.AppendString(": ")
+	if rec.Name != "" {
+		buf.AppendByte('[')
+		buf.AppendString(rec.Name)
+		buf.AppendString("] ")
+	}
+	appendSyslogMsg(buf, rec.Message)
+
+	lf := LogfmtEncoder{}
+	if rec.Caller.Defined() {
+		buf.AppendString(" caller=")
+		buf.AppendString(rec.Caller.String())
+	}
+	var pathBuf [4]string
+	path := pathBuf[:0]
+	for i, nf := 0, rec.NumFields(); i < nf; i++ {
+		f := rec.FieldAt(i)
+		if f.Type == FieldNamespace {
+			path = append(path, f.Key)
+			continue
+		}
+		lf.encodeField(buf, path, f)
+	}
+}
+
+// appendSyslogHeader writes a header field as printable ASCII without
+// spaces, truncated to max bytes, or "-" if empty.
+func appendSyslogHeader(buf *Buffer, s string, max int) {
+	if s == "" {
+		buf.AppendByte('-')
+		return
+	}
+	if len(s) > max {
+		s = s[:max]
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c <= ' ' || c > '~' {
+			c = '_'
+		}
+		buf.AppendByte(c)
+	}
+}
+
+// appendSDParam writes ` name="value"` for f, flattening groups into dotted
+// names. Structured errors are written as JSON, like objects.
+func appendSDParam(buf *Buffer, path []string, f *Field) {
+	if f.Type == FieldLazy {
+		r := f.resolveLazy()
+		f = &r
+	}
+	if f.Type == FieldGroup {
+		fields, _ := f.Iface.([]Field)
+		var pathBuf [8]string
+		sub := append(append(pathBuf[:0], path...), f.Key)
+		for i := range fields {
+			if fields[i].Type == FieldNamespace {
+				sub = append(sub, fields[i].Key)
+				continue
+			}
+			appendSDParam(buf, sub, &fields[i])
+		}
+		return
+	}
+
+	if f.Type == FieldError {
+		if obj, ok := f.asErrorObject(); ok {
+			f = &obj
+		}
+	}
+
+	buf.AppendByte(' ')
+	appendSDName(buf, path, f.Key)
+	buf.AppendString(`="`)
+	switch f.Type {
+	case FieldString, FieldError:
+		appendSDValue(buf, f.Str)
+	case FieldStringer:
+		appendSDValue(buf, stringerValue(f.Iface))
+	case FieldObject, FieldArray, FieldAny:
+		// JSON text may contain characters that need escaping.
+		tmp := getBuffer()
+		appendFieldPlain(tmp, f)
+		appendSDValue(buf, string(tmp.Bytes()))
+		putBuffer(tmp)
+	default:
+		appendFieldPlain(buf, f)
+	}
+	buf.AppendByte('"')
+}
+
+// appendSDName writes a PARAM-NAME: up to 32 printable ASCII characters
+// other than '=', ' ', ']', and '"'. Invalid characters become '_'.
+func appendSDName(buf *Buffer, path []string, key string) {
+	n := 0
+	write := func(s string) {
+		for i := 0; i < len(s) && n < 32; i++ {
+			c := s[i]
+			if c <= ' ' || c > '~' || c == '=' || c == ']' || c == '"' {
+				c = '_'
+			}
+			buf.AppendByte(c)
+			n++
+		}
+	}
+	for _, p := range path {
+		write(p)
+		write(".")
+	}
+	write(key)
+	if n == 0 {
+		buf.AppendByte('_')
+	}
+}
+
+// appendSDValue writes s with '"', '\', and ']' escaped, and line breaks
+// written as \n and \r so a record stays on one line.
+func appendSDValue(buf *Buffer, s string) {
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '"', '\\', ']':
+			buf.AppendString(s[start:i])
+			buf.AppendByte('\\')
+			buf.AppendByte(s[i])
+			start = i + 1
+		case '\n', '\r':
+			buf.AppendString(s[start:i])
+			appendLineBreak(buf, s[i])
+			start = i + 1
+		}
+	}
+	buf.AppendString(s[start:])
+}
+
+// appendSyslogMsg writes the MSG part with line breaks written as \n and
+// \r, since receivers using LF framing would split the record at them.
+func appendSyslogMsg(buf *Buffer, s string) {
+	start := 0
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\n' || s[i] == '\r' {
+			buf.AppendString(s[start:i])
+			appendLineBreak(buf, s[i])
+			start = i + 1
+		}
+	}
+	buf.AppendString(s[start:])
+}
+
+func appendLineBreak(buf *Buffer, c byte) {
+	if c == '\n' {
+		buf.AppendString(`\n`)
+	} else {
+		buf.AppendString(`\r`)
+	}
+}
@@ -0,0 +1,320 @@
+package loghq
+
+import (
+	"encoding/hex"
+	"math"
+	"sync"
+	"time"
+)
+
+// textFormat selects one of the key=value encoders (logfmt or console) so
+// nested groups, objects, and arrays share one flattening implementation.
+// Dispatch is a nil check rather than an interface call, which keeps the
+// temporary Fields built by the object encoders on the stack.
+type textFormat struct {
+	logfmt  *LogfmtEncoder
+	console *ConsoleEncoder
+}
+
+func (tf textFormat) appendKey(buf *Buffer, path []string, key string) {
+	if tf.console != nil {
+		tf.console.appendKey(buf, path, key)
+		return
+	}
+	tf.logfmt.appendKey(buf, path, key)
+}
+
+func (tf textFormat) encodeValue(buf *Buffer, f *Field) {
+	if tf.console != nil {
+		tf.console.encodeValue(buf, f)
+		return
+	}
+	tf.logfmt.encodeValue(buf, f)
+}
+
+// appendTextScalar writes the value types that logfmt and console output
+// render identically: unsigned integers, hex-encoded bytes, and complex
+// numbers.
+func appendTextScalar(buf *Buffer, f *Field) {
+	switch f.Type {
+	case FieldUint64:
+		buf.AppendUint(uint64(f.Ival))
+	case FieldBytes:
+		b, _ := f.Iface.([]byte)
+		buf.B = hex.AppendEncode(buf.B, b)
+	case FieldComplex:
+		c, _ := f.Iface.(complex128)
+		buf.AppendComplex(c)
+	}
+}
+
+// appendFieldPlain writes the value of a non-group field as unquoted text:
+// strings as-is, scalars in their usual text form, and objects, arrays,
+// structured errors, and arbitrary values as JSON.
+func appendFieldPlain(buf *Buffer, f *Field) {
+	switch f.Type {
+	case FieldString:
+		buf.AppendString(f.Str)
+	case FieldError:
+		if obj, ok := f.asErrorObject(); ok {
+			(&JSONEncoder{}).encodeValue(buf, &obj)
+		} else {
+			buf.AppendString(f.Str)
+		}
+	case FieldStringer:
+		buf.AppendString(stringerValue(f.Iface))
+	case FieldTime:
+		if t, ok := f.Iface.(time.Time); ok {
+			buf.AppendTime(t, time.RFC3339Nano)
+		}
+	case FieldUint64, FieldBytes, FieldComplex:
+		appendTextScalar(buf, f)
+	default:
+		if b, ok := appendFieldScalar(buf.B, f); ok {
+			buf.B = b
+			return
+		}
+		(&JSONEncoder{}).encodeValue(buf, f)
+	}
+}
+
+var (
+	textObjectEncoderPool = sync.Pool{New: func() interface{} { return &textObjectEncoder{} }}
+	textArrayEncoderPool  = sync.Pool{New: func() interface{} { return &textArrayEncoder{} }}
+)
+
+// encodeTextNested flattens a group or object field into dotted keys
+// (user.name=ali user.id=42), each preceded by a space.
+func encodeTextNested(tf textFormat, buf *Buffer, path []string, f *Field) {
+	o := getTextObjectEncoder(tf, buf, false)
+	o.path = append(o.path, path...)
+	o.add(f)
+	o.release()
+}
+
+// encodeTextComposite writes the value of an object, group, or array field
+// in inline form: {k=v,k=v} for objects and [v,v] for arrays.
+func encodeTextComposite(tf textFormat, buf *Buffer, f *Field) {
+	switch f.Type {
+	case FieldArray:
+		m, _ := f.Iface.(ArrayMarshaler)
+		a := textArrayEncoderPool.Get().(*textArrayEncoder)
+		a.fmt, a.buf, a.n = tf, buf, 0
+		buf.AppendByte('[')
+		if m != nil {
+			if err := m.MarshalLogArray(a); err != nil {
+				a.AppendString(err.Error())
+			}
+		}
+		buf.AppendByte(']')
+		a.fmt, a.buf = textFormat{}, nil
+		textArrayEncoderPool.Put(a)
+	case FieldObject:
+		m, _ := f.Iface.(ObjectMarshaler)
+		o := getTextObjectEncoder(tf, buf, true)
+		buf.AppendByte('{')
+		if m != nil {
+			if err := m.MarshalLogObject(o); err != nil {
+				o.EncodeError("error", err.Error())
+			}
+		}
+		buf.AppendByte('}')
+		o.release()
+	case FieldGroup:
+		fields, _ := f.Iface.([]Field)
+		o := getTextObjectEncoder(tf, buf, true)
+		buf.AppendByte('{')
+		for i := range fields {
+			o.add(&fields[i])
+		}
+		buf.AppendByte('}')
+		o.release()
+	}
+}
+
+func getTextObjectEncoder(tf textFormat, buf *Buffer, inline bool) *textObjectEncoder {
+	o := textObjectEncoderPool.Get().(*textObjectEncoder)
+	o.fmt, o.buf, o.inline, o.n = tf, buf, inline, 0
+	o.path = o.pathBuf[:0]
+	return o
+}
+
+// textObjectEncoder implements FieldEncoder for the key=value formats.
+// Nested keys are prefixed with the enclosing names in path. Top-level
+// objects separate members with spaces; inline ones (inside arrays) use
+// commas. Instances are pooled so ObjectMarshaler calls don't allocate.
+type textObjectEncoder struct {
+	fmt     textFormat
+	buf     *Buffer
+	pathBuf [8]string
+	path    []string
+	inline  bool
+	n       int
+}
+
+func (o *textObjectEncoder) release() {
+	clear(o.pathBuf[:])
+	o.fmt, o.buf, o.path = textFormat{}, nil, nil
+	textObjectEncoderPool.Put(o)
+}
+
+func (o *textObjectEncoder) add(f *Field) {
+	if f.Type == FieldLazy {
+		r := f.resolveLazy()
+		f = &r
+	}
+	switch f.Type {
+	case FieldNamespace:
+		o.path = append(o.path, f.Key)
+		return
+	case FieldGroup:
+		n := len(o.path)
+		o.path = append(o.path, f.Key)
+		fields, _ := f.Iface.([]Field)
+		for i := range fields {
+			o.add(&fields[i])
+		}
+		o.path = o.path[:n]
+		return
+	case FieldError:
+		if obj, ok := f.asErrorObject(); ok {
+			o.add(&obj)
+			return
+		}
+	case FieldObject:
+		n := len(o.path)
+		o.path = append(o.path, f.Key)
+		if m, ok := f.Iface.(ObjectMarshaler); ok {
+			if err := m.MarshalLogObject(o); err != nil {
+				o.EncodeError("error", err.Error())
+			}
+		}
+		o.path = o.path[:n]
+		return
+	}
+
+	if !o.inline {
+		o.buf.AppendByte(' ')
+	} else if o.n > 0 {
+		o.buf.AppendByte(',')
+	}
+	o.n++
+	o.fmt.appendKey(o.buf, o.path, f.Key)
+	o.fmt.encodeValue(o.buf, f)
+}
+
+func (o *textObjectEncoder) EncodeString(key, val string) {
+	o.add(&Field{Key: key, Type: FieldString, Str: val})
+}
+
+func (o *textObjectEncoder) EncodeInt64(key string, val int64) {
+	o.add(&Field{Key: key, Type: FieldInt64, Ival: val})
+}
+
+func (o *textObjectEncoder) EncodeUint64(key string, val uint64) {
+	o.add(&Field{Key: key, Type: FieldUint64, Ival: int64(val)})
+}
+
+func (o *textObjectEncoder) EncodeBytes(key string, val []byte) {
+	o.add(&Field{Key: key, Type: FieldBytes, Iface: val})
+}
+
+func (o *textObjectEncoder) EncodeComplex128(key string, val complex128) {
+	o.add(&Field{Key: key, Type: FieldComplex, Iface: val})
+}
+
+func (o *textObjectEncoder) EncodeFloat64(key string, val float64) {
+	o.add(&Field{Key: key, Type: FieldFloat64, Ival: int64(math.Float64bits(val))})
+}
+
+func (o *textObjectEncoder) EncodeBool(key string, val bool) {
+	o.add(&Field{Key: key, Type: FieldBool, Ival: boolToInt64(val)})
+}
+
+func (o *textObjectEncoder) EncodeDuration(key string, val time.Duration) {
+	o.add(&Field{Key: key, Type: FieldDuration, Ival: int64(val)})
+}
+
+func (o *textObjectEncoder) EncodeTime(key string, val time.Time) {
+	o.add(&Field{Key: key, Type: FieldTime, Iface: val})
+}
+
+func (o *textObjectEncoder) EncodeError(key string, msg string) {
+	o.add(&Field{Key: key, Type: FieldError, Str: msg})
+}
+
+func (o *textObjectEncoder) EncodeAny(key string, val interface{}) {
+	o.add(&Field{Key: key, Type: FieldAny, Iface: val})
+}
+
+func (o *textObjectEncoder) EncodeGroup(key string, fields []Field) {
+	o.add(&Field{Key: key, Type: FieldGroup, Iface: fields})
+}
+
+func (o *textObjectEncoder) OpenNamespace(key string) {
+	o.add(&Field{Key: key, Type: FieldNamespace})
+}
+
+func (o *textObjectEncoder) EncodeObject(key string, obj ObjectMarshaler) {
+	o.add(&Field{Key: key, Type: FieldObject, Iface: obj})
+}
+
+func (o *textObjectEncoder) EncodeArray(key string, arr ArrayMarshaler) {
+	o.add(&Field{Key: key, Type: FieldArray, Iface: arr})
+}
+
+// textArrayEncoder implements ArrayEncoder for the key=value formats,
+// writing comma-separated elements.
+type textArrayEncoder struct {
+	fmt textFormat
+	buf *Buffer
+	n   int
+}
+
+func (a *textArrayEncoder) add(f *Field) {
+	if a.n > 0 {
+		a.buf.AppendByte(',')
+	}
+	a.n++
+	a.fmt.encodeValue(a.buf, f)
+}
+
+func (a *textArrayEncoder) AppendString(val string) {
+	a.add(&Field{Type: FieldString, Str: val})
+}
+
+func (a *textArrayEncoder) AppendInt64(val int64) {
+	a.add(&Field{Type: FieldInt64, Ival: val})
+}
+
+func (a *textArrayEncoder) AppendUint64(val uint64) {
+	a.add(&Field{Type: FieldUint64, Ival: int64(val)})
+}
+
+func (a *textArrayEncoder) AppendFloat64(val float64) {
+	a.add(&Field{Type: FieldFloat64, Ival: int64(math.Float64bits(val))})
+}
+
+func (a *textArrayEncoder) AppendBool(val bool) {
+	a.add(&Field{Type: FieldBool, Ival: boolToInt64(val)})
+}
+
+func (a *textArrayEncoder) AppendDuration(val time.Duration) {
+	a.add(&Field{Type: FieldDuration, Ival: int64(val)})
+}
+
+func (a *textArrayEncoder) AppendTime(val time.Time) {
+	a.add(&Field{Type: FieldTime, Iface: val})
+}
+
+func (a *textArrayEncoder) AppendAny(val interface{}) {
+	a.add(&Field{Type: FieldAny, Iface: val})
+}
+
+func (a *textArrayEncoder) AppendObject(obj ObjectMarshaler) {
+	a.add(&Field{Type: FieldObject, Iface: obj})
+}
+
+func (a *textArrayEncoder) AppendArray(arr ArrayMarshaler) {
+	a.add(&Field{Type: FieldArray, Iface: arr})
+}
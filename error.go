@@ -0,0 +1,222 @@
+package loghq
+
+import (
+	"errors"
+	"reflect"
+	"runtime"
+	"strconv"
+	"sync"
+)
+
+// LogFielder is implemented by errors that carry structured context. The
+// fields are logged inside the error's object, next to its message and
+// type.
+//
+//	func (e *QueryError) LogFields() []loghq.Field {
+//		return []loghq.Field{loghq.String("table", e.Table), loghq.Int("rows", e.Rows)}
+//	}
+type LogFielder interface {
+	LogFields() []Field
+}
+
+// maxErrorDepth bounds how far an Unwrap chain is followed, so an error
+// that unwraps to itself cannot hang the encoder. It also caps the frames
+// taken from an error's stack trace, matching captureStack.
+const maxErrorDepth = 32
+
+// asErrorObject returns f, an error field, as an object field when its
+// error, or any error it wraps or joins, carries log fields or a stack
+// trace. The object holds:
+//
+//	msg     err.Error()
+//	type    the concrete type, e.g. *fs.PathError
+//	...     the error's LogFields, if any
+//	stack   the innermost stack trace in the Unwrap chain, one
+//	        "function file:line" string per frame
+//	causes  each error in the Unwrap chain, or the members of an
+//	        errors.Join, as objects of the same shape
+//
+// Other errors, including plain ones wrapped with %w, keep logging as
+// their message.
+func (f *Field) asErrorObject() (Field, bool) {
+	err, ok := f.Iface.(error)
+	if !ok || !hasErrorDetail(err, 0) {
+		return Field{}, false
+	}
+	return Field{Key: f.Key, Type: FieldObject, Iface: errorObject{msg: f.Str, err: err, full: true}}, true
+}
+
+// hasErrorDetail reports whether err or an error in its Unwrap tree has
+// log fields or a stack trace. depth counts the errors already visited.
+func hasErrorDetail(err error, depth int) bool {
+	for ; err != nil && depth < maxErrorDepth; depth++ {
+		switch err.(type) {
+		case LogFielder, framer:
+			return true
+		}
+		if _, ok := stackTraceMethod(err); ok {
+			return true
+		}
+		if j, ok := err.(interface{ Unwrap() []error }); ok {
+			for _, e := range j.Unwrap() {
+				if hasErrorDetail(e, depth+1) {
+					return true
+				}
+			}
+			return false
+		}
+		err = errors.Unwrap(err)
+	}
+	return false
+}
+
+// errorObject encodes one error. Elements of a causes array are not full:
+// the chain is already flattened around them, and the stack is reported
+// once, by the outermost error.
+type errorObject struct {
+	msg  string
+	err  error
+	full bool
+}
+
+func (o errorObject) MarshalLogObject(enc FieldEncoder) error {
+	enc.EncodeString("msg", o.msg)
+	enc.EncodeString("type", reflect.TypeOf(o.err).String())
+	if lf, ok := o.err.(LogFielder); ok {
+		for _, f := range lf.LogFields() {
+			f.Encode(enc)
+		}
+	}
+	if !o.full {
+		return nil
+	}
+	if frames := errorFrames(o.err); len(frames) > 0 {
+		enc.EncodeArray("stack", frameArray(frames))
+	}
+	if hasErrorCauses(o.err) {
+		enc.EncodeArray("causes", errorCauses{o.err})
+	}
+	return nil
+}
+
+func hasErrorCauses(err error) bool {
+	if j, ok := err.(interface{ Unwrap() []error }); ok {
+		return len(j.Unwrap()) > 0
+	}
+	return errors.Unwrap(err) != nil
+}
+
+// errorCauses lists what an error wraps: each error down its Unwrap chain,
+// or the members of an errors.Join. A joined error met in the chain ends
+// it and lists its own members as causes.
+type errorCauses struct{ err error }
+
+func (c errorCauses) MarshalLogArray(enc ArrayEncoder) error {
+	if j, ok := c.err.(interface{ Unwrap() []error }); ok {
+		for _, e := range j.Unwrap() {
+			if e != nil {
+				enc.AppendObject(errorObject{msg: e.Error(), err: e, full: true})
+			}
+		}
+		return nil
+	}
+	e := c.err
+	for i := 0; i < maxErrorDepth; i++ {
+		if e = errors.Unwrap(e); e == nil {
+			break
+		}
+		if _, ok := e.(interface{ Unwrap() []error }); ok {
+			enc.AppendObject(errorObject{msg: e.Error(), err: e, full: true})
+			break
+		}
+		enc.AppendObject(errorObject{msg: e.Error(), err: e})
+	}
+	return nil
+}
+
+// framer is implemented by errors that report their stack as frames.
+type framer interface {
+	Frames() []runtime.Frame
+}
+
+// stackTraceMethods caches stackTraceMethod results by error type.
+var stackTraceMethods sync.Map // reflect.Type -> stackTrace
+
+type stackTrace struct {
+	m  reflect.Method
+	ok bool
+}
+
+// stackTraceMethod returns err's StackTrace method if it takes no
+// arguments and returns a slice of program counters, as the StackTrace
+// method of github.com/pkg/errors does. Reflection matches the method
+// without importing the package that defines the slice type; the result
+// is cached per type, since every error field asks.
+func stackTraceMethod(err error) (reflect.Method, bool) {
+	t := reflect.TypeOf(err)
+	if v, ok := stackTraceMethods.Load(t); ok {
+		st := v.(stackTrace)
+		return st.m, st.ok
+	}
+	m, ok := t.MethodByName("StackTrace")
+	if ok && m.Type.NumIn() == 1 && m.Type.NumOut() == 1 {
+		out := m.Type.Out(0)
+		ok = out.Kind() == reflect.Slice && out.Elem().Kind() == reflect.Uintptr
+	} else {
+		ok = false
+	}
+	stackTraceMethods.Store(t, stackTrace{m, ok})
+	return m, ok
+}
+
+// errorFrames returns the stack of the innermost error in err's Unwrap
+// chain that has one: the point where the failure originated.
+func errorFrames(err error) []runtime.Frame {
+	var src error
+	for i := 0; err != nil && i < maxErrorDepth; i++ {
+		if _, ok := err.(framer); ok {
+			src = err
+		} else if _, ok := stackTraceMethod(err); ok {
+			src = err
+		}
+		err = errors.Unwrap(err)
+	}
+	if src == nil {
+		return nil
+	}
+	if f, ok := src.(framer); ok {
+		frames := f.Frames()
+		return frames[:min(len(frames), maxErrorDepth)]
+	}
+
+	m, _ := stackTraceMethod(src)
+	st := m.Func.Call([]reflect.Value{reflect.ValueOf(src)})[0]
+	n := min(st.Len(), maxErrorDepth)
+	if n == 0 {
+		return nil
+	}
+	pcs := make([]uintptr, n)
+	for i := range pcs {
+		pcs[i] = uintptr(st.Index(i).Uint())
+	}
+	frames := make([]runtime.Frame, 0, n)
+	it := runtime.CallersFrames(pcs)
+	for {
+		frame, more := it.Next()
+		frames = append(frames, frame)
+		if !more {
+			break
+		}
+	}
+	return frames
+}
+
+// frameArray renders stack frames as "function file:line" strings.
+type frameArray []runtime.Frame
+
+func (a frameArray) MarshalLogArray(enc ArrayEncoder) error {
+	for _, f := range a {
+		enc.AppendString(f.Function + " " + f.File + ":" + strconv.Itoa(f.Line))
+	}
+	return nil
+}
@@ -3,6 +3,7 @@ package loghq
 import (
 	"fmt"
 	"math"
+	"reflect"
 	"time"
 )
 
@@ -18,6 +19,15 @@ const (
 	FieldDuration
 	FieldTime
 	FieldAny
+	FieldGroup
+	FieldNamespace
+	FieldObject
+	FieldArray
+	FieldUint64
+	FieldBytes
+	FieldComplex
+	FieldStringer
+	FieldLazy
 )
 
 // Field is a typed key-value pair. Using a tagged union avoids interface boxing
@@ -47,23 +57,31 @@ func Int64(key string, val int64) Field {
 	return Field{Key: key, Type: FieldInt64, Ival: val}
 }
 
+func Uint(key string, val uint) Field {
+	return Field{Key: key, Type: FieldUint64, Ival: int64(val)}
+}
+
+func Uint64(key string, val uint64) Field {
+	return Field{Key: key, Type: FieldUint64, Ival: int64(val)}
+}
+
 func Float64(key string, val float64) Field {
 	return Field{Key: key, Type: FieldFloat64, Ival: int64(math.Float64bits(val))}
 }
 
 func Bool(key string, val bool) Field {
-	var v int64
-	if val {
-		v = 1
-	}
-	return Field{Key: key, Type: FieldBool, Ival: v}
+	return Field{Key: key, Type: FieldBool, Ival: boolToInt64(val)}
 }
 
+// Err logs err under the key "error". Errors that wrap others, join
+// several, carry a stack trace, or implement LogFielder are encoded as an
+// object with their type, causes, and stack; others log their message.
+// Key-value pairs holding an error are converted the same way.
 func Err(err error) Field {
 	if err == nil {
 		return Field{Key: "error", Type: FieldString, Str: "<nil>"}
 	}
-	return Field{Key: "error", Type: FieldError, Str: err.Error()}
+	return Field{Key: "error", Type: FieldError, Str: err.Error(), Iface: err}
 }
 
 func Duration(key string, d time.Duration) Field {
@@ -74,10 +92,121 @@ func Time(key string, t time.Time) Field {
 	return Field{Key: key, Type: FieldTime, Iface: t}
 }
 
+// Bytes logs raw bytes: base64 in JSON (hex with WithJSONHexBytes) and hex
+// in logfmt and console output.
+func Bytes(key string, val []byte) Field {
+	return Field{Key: key, Type: FieldBytes, Iface: val}
+}
+
+func Complex128(key string, val complex128) Field {
+	return Field{Key: key, Type: FieldComplex, Iface: val}
+}
+
+// Stringer logs val.String(), called only when the record is encoded.
+func Stringer(key string, val fmt.Stringer) Field {
+	return Field{Key: key, Type: FieldStringer, Iface: val}
+}
+
+func Strings(key string, val []string) Field {
+	return Field{Key: key, Type: FieldArray, Iface: stringArray(val)}
+}
+
+func Ints(key string, val []int) Field {
+	return Field{Key: key, Type: FieldArray, Iface: intArray(val)}
+}
+
+func Float64s(key string, val []float64) Field {
+	return Field{Key: key, Type: FieldArray, Iface: float64Array(val)}
+}
+
+func Durations(key string, val []time.Duration) Field {
+	return Field{Key: key, Type: FieldArray, Iface: durationArray(val)}
+}
+
+// Lazy defers computing a field's value until the record is known to be
+// logged. fn runs once per log call, after the level check, and its result
+// is converted like a key-value pair.
+func Lazy(key string, fn func() interface{}) Field {
+	return Field{Key: key, Type: FieldLazy, Iface: fn}
+}
+
+// LazyField defers building an entire field until the record is known to
+// be logged. fn runs once per log call, after the level check.
+func LazyField(fn func() Field) Field {
+	return Field{Type: FieldLazy, Iface: fn}
+}
+
+// resolveLazy evaluates a FieldLazy, returning the computed field. Lazy
+// members of a group, whether f is the group or computes it, are resolved
+// as well.
+func (f *Field) resolveLazy() Field {
+	r := *f
+	if r.Type == FieldLazy {
+		switch fn := f.Iface.(type) {
+		case func() interface{}:
+			r = toField(f.Key, fn())
+		case func() Field:
+			r = fn()
+		default:
+			r = Field{Key: f.Key, Type: FieldString, Str: "<nil>"}
+		}
+	}
+	if r.Type == FieldGroup {
+		fields, _ := r.Iface.([]Field)
+		r.Iface = resolveLazyFields(fields)
+	}
+	return r
+}
+
+// resolveLazyFields returns fields with every lazy member resolved. The
+// slice is copied only when it holds one, so a group bound with With is
+// never modified.
+func resolveLazyFields(fields []Field) []Field {
+	for i := range fields {
+		if !hasLazy(&fields[i]) {
+			continue
+		}
+		cp := make([]Field, len(fields))
+		copy(cp, fields)
+		for j := i; j < len(cp); j++ {
+			cp[j] = cp[j].resolveLazy()
+		}
+		return cp
+	}
+	return fields
+}
+
+func hasLazy(f *Field) bool {
+	switch f.Type {
+	case FieldLazy:
+		return true
+	case FieldGroup:
+		fields, _ := f.Iface.([]Field)
+		for i := range fields {
+			if hasLazy(&fields[i]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func Any(key string, val interface{}) Field {
 	return Field{Key: key, Type: FieldAny, Iface: val}
 }
 
+// Group nests fields under key. JSON renders a nested object; logfmt and
+// console flatten to dotted keys (http.method=GET).
+func Group(key string, fields ...Field) Field {
+	return Field{Key: key, Type: FieldGroup, Iface: fields}
+}
+
+// Namespace opens a scope named key: every field that follows it on the
+// same record is nested under key. See Logger.WithGroup.
+func Namespace(key string) Field {
+	return Field{Key: key, Type: FieldNamespace}
+}
+
 // parseKVPairs converts slog-style alternating key-value pairs into typed Fields.
 // Uses type switches instead of reflection for zero-alloc on common types.
 func parseKVPairs(kvs []interface{}) []Field {
@@ -128,9 +257,11 @@ func toField(key string, val interface{}) Field {
 	case int8:
 		return Field{Key: key, Type: FieldInt64, Ival: int64(v)}
 	case uint:
-		return Field{Key: key, Type: FieldInt64, Ival: int64(v)}
+		return Field{Key: key, Type: FieldUint64, Ival: int64(v)}
 	case uint64:
-		return Field{Key: key, Type: FieldInt64, Ival: int64(v)}
+		return Field{Key: key, Type: FieldUint64, Ival: int64(v)}
+	case uintptr:
+		return Field{Key: key, Type: FieldUint64, Ival: int64(v)}
 	case uint32:
 		return Field{Key: key, Type: FieldInt64, Ival: int64(v)}
 	case uint16:
@@ -141,25 +272,39 @@ func toField(key string, val interface{}) Field {
 		return Field{Key: key, Type: FieldFloat64, Ival: int64(math.Float64bits(v))}
 	case float32:
 		return Field{Key: key, Type: FieldFloat64, Ival: int64(math.Float64bits(float64(v)))}
+	case complex128:
+		return Field{Key: key, Type: FieldComplex, Iface: v}
+	case complex64:
+		return Field{Key: key, Type: FieldComplex, Iface: complex128(v)}
 	case bool:
-		var iv int64
-		if v {
-			iv = 1
-		}
-		return Field{Key: key, Type: FieldBool, Ival: iv}
+		return Field{Key: key, Type: FieldBool, Ival: boolToInt64(v)}
 	case error:
 		if v == nil {
 			return Field{Key: key, Type: FieldString, Str: "<nil>"}
 		}
-		return Field{Key: key, Type: FieldError, Str: v.Error()}
+		return Field{Key: key, Type: FieldError, Str: v.Error(), Iface: v}
 	case time.Duration:
 		return Field{Key: key, Type: FieldDuration, Ival: int64(v)}
 	case time.Time:
 		return Field{Key: key, Type: FieldTime, Iface: v}
+	case []byte:
+		return Field{Key: key, Type: FieldBytes, Iface: v}
+	case []string:
+		return Field{Key: key, Type: FieldArray, Iface: stringArray(v)}
+	case []int:
+		return Field{Key: key, Type: FieldArray, Iface: intArray(v)}
+	case []float64:
+		return Field{Key: key, Type: FieldArray, Iface: float64Array(v)}
+	case []time.Duration:
+		return Field{Key: key, Type: FieldArray, Iface: durationArray(v)}
 	case Field:
 		// Allow passing typed Field directly
 		v.Key = key
 		return v
+	case ObjectMarshaler:
+		return Field{Key: key, Type: FieldObject, Iface: v}
+	case ArrayMarshaler:
+		return Field{Key: key, Type: FieldArray, Iface: v}
 	default:
 		return Field{Key: key, Type: FieldAny, Iface: v}
 	}
@@ -185,9 +330,39 @@ func (f *Field) Encode(enc FieldEncoder) {
 			enc.EncodeTime(f.Key, t)
 		}
 	case FieldError:
-		enc.EncodeError(f.Key, f.Str)
+		if obj, ok := f.asErrorObject(); ok {
+			obj.Encode(enc)
+		} else {
+			enc.EncodeError(f.Key, f.Str)
+		}
 	case FieldAny:
 		enc.EncodeAny(f.Key, f.Iface)
+	case FieldGroup:
+		fields, _ := f.Iface.([]Field)
+		enc.EncodeGroup(f.Key, fields)
+	case FieldNamespace:
+		enc.OpenNamespace(f.Key)
+	case FieldObject:
+		if m, ok := f.Iface.(ObjectMarshaler); ok {
+			enc.EncodeObject(f.Key, m)
+		}
+	case FieldArray:
+		if m, ok := f.Iface.(ArrayMarshaler); ok {
+			enc.EncodeArray(f.Key, m)
+		}
+	case FieldUint64:
+		enc.EncodeUint64(f.Key, uint64(f.Ival))
+	case FieldBytes:
+		b, _ := f.Iface.([]byte)
+		enc.EncodeBytes(f.Key, b)
+	case FieldComplex:
+		c, _ := f.Iface.(complex128)
+		enc.EncodeComplex128(f.Key, c)
+	case FieldStringer:
+		enc.EncodeString(f.Key, stringerValue(f.Iface))
+	case FieldLazy:
+		r := f.resolveLazy()
+		r.Encode(enc)
 	}
 }
 
@@ -200,6 +375,32 @@ func fieldsFromMap(m Fields) []Field {
 	return fields
 }
 
+func boolToInt64(b bool) int64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// stringerValue calls String on v, reporting nil receivers and panics
+// instead of crashing the logging goroutine.
+func stringerValue(v interface{}) (s string) {
+	st, ok := v.(fmt.Stringer)
+	if !ok || st == nil {
+		return "<nil>"
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			if rv := reflect.ValueOf(st); rv.Kind() == reflect.Pointer && rv.IsNil() {
+				s = "<nil>"
+				return
+			}
+			s = fmt.Sprintf("<PANIC=%v>", r)
+		}
+	}()
+	return st.String()
+}
+
 // formatAny formats an arbitrary value as a string.
 func formatAny(v interface{}) string {
 	if v == nil {
@@ -1,7 +1,9 @@
 package loghq
 
 import (
+	"bytes"
 	"compress/gzip"
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
@@ -15,19 +17,49 @@ import (
 // FileConfig configures the FileWriter.
 type FileConfig struct {
 	// Path is the log file path.
-	Path string
+	Path string `json:"path"`
 
 	// MaxSize is the maximum size in bytes before rotation. Default: 100MB.
-	MaxSize int64
+	MaxSize int64 `json:"max_size"`
 
 	// MaxAge is how long to keep old log files. Default: 7 days. 0 means no limit.
-	MaxAge time.Duration
+	// In JSON it may be a duration string ("168h") or nanoseconds.
+	MaxAge time.Duration `json:"max_age"`
 
 	// MaxBackups is the maximum number of old log files to keep. Default: 5. 0 means no limit.
-	MaxBackups int
+	MaxBackups int `json:"max_backups"`
 
 	// Compress enables gzip compression of rotated files.
-	Compress bool
+	Compress bool `json:"compress"`
+}
+
+// UnmarshalJSON accepts MaxAge as either a duration string or a number.
+// Unknown keys are rejected, as in ParseJSONConfig.
+func (c *FileConfig) UnmarshalJSON(data []byte) error {
+	type plain FileConfig
+	aux := struct {
+		*plain
+		MaxAge interface{} `json:"max_age"`
+	}{plain: (*plain)(c)}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&aux); err != nil {
+		return err
+	}
+	switch v := aux.MaxAge.(type) {
+	case nil:
+	case string:
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("loghq: invalid max_age: %w", err)
+		}
+		c.MaxAge = d
+	case float64:
+		c.MaxAge = time.Duration(v)
+	default:
+		return fmt.Errorf("loghq: invalid max_age %v", v)
+	}
+	return nil
 }
 
 func (c *FileConfig) maxSize() int64 {
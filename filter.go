@@ -0,0 +1,550 @@
+package loghq
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Filter is a compiled record predicate. Compile once with CompileFilter;
+// Match is safe for concurrent use and does not allocate for records whose
+// matched fields are strings, numbers, booleans, or durations.
+//
+// The language:
+//
+//	level>=warn                       level names compare by severity
+//	msg=="ping" || msg=~"^GET /"      msg, caller (file), and logger are built in
+//	path!="/healthz"                  any other name is a top-level field key
+//	status>=500 && latency>250ms      numbers and durations compare numerically
+//	user                              a bare name tests field presence
+//	!(method=="OPTIONS")              !, &&, ||, and parentheses
+//
+// Comparison operators are ==, !=, <, <=, >, >=, =~ (regexp match), and
+// !~ (regexp non-match). A comparison against a missing field is false,
+// except != and !~, which are true. Values are quoted strings, numbers,
+// durations, true/false, or bare words.
+type Filter struct {
+	expr string
+	root filterNode
+}
+
+// CompileFilter parses expr into a Filter.
+func CompileFilter(expr string) (*Filter, error) {
+	p := &filterParser{src: expr}
+	p.next()
+	root, err := p.parseOr()
+	if err == nil {
+		// A lexing error after the last term ends the input early.
+		err = p.err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("loghq: filter %q: %w", expr, err)
+	}
+	if p.tok.kind != tokEOF {
+		return nil, fmt.Errorf("loghq: filter %q: unexpected %s at offset %d", expr, p.tok, p.tok.pos)
+	}
+	return &Filter{expr: expr, root: root}, nil
+}
+
+// MustCompileFilter is like CompileFilter but panics on error.
+func MustCompileFilter(expr string) *Filter {
+	f, err := CompileFilter(expr)
+	if err != nil {
+		panic(err)
+	}
+	return f
+}
+
+// Match reports whether rec satisfies the filter.
+func (f *Filter) Match(rec *Record) bool {
+	return f.root.eval(rec)
+}
+
+// String returns the source expression.
+func (f *Filter) String() string {
+	return f.expr
+}
+
+// --- Evaluation ---
+
+type filterNode interface {
+	eval(rec *Record) bool
+}
+
+type (
+	filterOr    struct{ l, r filterNode }
+	filterAnd   struct{ l, r filterNode }
+	filterNot   struct{ x filterNode }
+	filterConst bool
+)
+
+func (n *filterOr) eval(rec *Record) bool  { return n.l.eval(rec) || n.r.eval(rec) }
+func (n *filterAnd) eval(rec *Record) bool { return n.l.eval(rec) && n.r.eval(rec) }
+func (n *filterNot) eval(rec *Record) bool { return !n.x.eval(rec) }
+func (n filterConst) eval(*Record) bool    { return bool(n) }
+
+type filterOp uint8
+
+const (
+	opEq filterOp = iota
+	opNe
+	opLt
+	opLe
+	opGt
+	opGe
+	opMatch
+	opNoMatch
+)
+
+var filterOpNames = [...]string{"==", "!=", "<", "<=", ">", ">=", "=~", "!~"}
+
+// test applies op to the result of a three-way comparison.
+func (op filterOp) test(cmp int) bool {
+	switch op {
+	case opEq:
+		return cmp == 0
+	case opNe:
+		return cmp != 0
+	case opLt:
+		return cmp < 0
+	case opLe:
+		return cmp <= 0
+	case opGt:
+		return cmp > 0
+	default:
+		return cmp >= 0
+	}
+}
+
+// missing is the result of comparing against an absent field.
+func (op filterOp) missing() bool {
+	return op == opNe || op == opNoMatch
+}
+
+type filterLevel struct {
+	op  filterOp
+	lvl Level
+}
+
+func (n *filterLevel) eval(rec *Record) bool {
+	return n.op.test(int(rec.Level) - int(n.lvl))
+}
+
+// filterTarget selects the string a built-in comparison reads.
+type filterTarget uint8
+
+const (
+	targetField filterTarget = iota
+	targetMessage
+	targetCaller
+	targetLogger
+)
+
+// filterValue is a literal on the right-hand side of a comparison.
+type filterValue struct {
+	str     string
+	num     float64
+	isNum   bool
+	isBool  bool
+	boolean bool
+	re      *regexp.Regexp
+}
+
+type filterCompare struct {
+	target filterTarget
+	key    string
+	op     filterOp
+	val    filterValue
+}
+
+func (n *filterCompare) eval(rec *Record) bool {
+	switch n.target {
+	case targetMessage:
+		return n.compareString(rec.Message)
+	case targetCaller:
+		if !rec.Caller.Defined() {
+			return n.op.missing()
+		}
+		return n.compareString(rec.Caller.File)
+	case targetLogger:
+		return n.compareString(rec.Name)
+	}
+
+	f, ok := rec.Lookup(n.key)
+	if !ok {
+		return n.op.missing()
+	}
+	switch f.Type {
+	case FieldString, FieldError:
+		return n.compareString(f.Str)
+	case FieldBool:
+		if n.val.isBool && n.op <= opNe {
+			return n.op.test(boolCompare(f.Ival == 1, n.val.boolean))
+		}
+	}
+	if n.val.isNum && n.op < opMatch {
+		if v, ok := fieldNumber(f); ok {
+			return n.op.test(floatCompare(v, n.val.num))
+		}
+	}
+
+	var tmp [64]byte
+	b, ok := appendFieldScalar(tmp[:0], f)
+	if !ok {
+		return n.compareString(fieldValueString(f))
+	}
+	if n.val.re != nil {
+		return n.val.re.Match(b) == (n.op == opMatch)
+	}
+	return n.op.test(compareBytesString(b, n.val.str))
+}
+
+func (n *filterCompare) compareString(s string) bool {
+	if n.val.re != nil {
+		return n.val.re.MatchString(s) == (n.op == opMatch)
+	}
+	if n.val.isNum && n.op >= opLt {
+		if v, err := strconv.ParseFloat(s, 64); err == nil {
+			return n.op.test(floatCompare(v, n.val.num))
+		}
+		return false
+	}
+	return n.op.test(strings.Compare(s, n.val.str))
+}
+
+type filterPresent struct{ key string }
+
+func (n *filterPresent) eval(rec *Record) bool {
+	_, ok := rec.Lookup(n.key)
+	return ok
+}
+
+// fieldNumber returns numeric field values as float64. Durations are in
+// nanoseconds, matching duration literals.
+func fieldNumber(f *Field) (float64, bool) {
+	switch f.Type {
+	case FieldInt64, FieldDuration:
+		return float64(f.Ival), true
+	case FieldUint64:
+		return float64(uint64(f.Ival)), true
+	case FieldFloat64:
+		return math.Float64frombits(uint64(f.Ival)), true
+	}
+	return 0, false
+}
+
+// appendFieldScalar appends the text of a scalar field to dst. It reports
+// false for types that need formatting through fieldValueString.
+func appendFieldScalar(dst []byte, f *Field) ([]byte, bool) {
+	switch f.Type {
+	case FieldInt64:
+		return strconv.AppendInt(dst, f.Ival, 10), true
+	case FieldUint64:
+		return strconv.AppendUint(dst, uint64(f.Ival), 10), true
+	case FieldFloat64:
+		return strconv.AppendFloat(dst, math.Float64frombits(uint64(f.Ival)), 'f', -1, 64), true
+	case FieldBool:
+		return strconv.AppendBool(dst, f.Ival == 1), true
+	case FieldDuration:
+		return append(dst, time.Duration(f.Ival).String()...), true
+	}
+	return dst, false
+}
+
+func floatCompare(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func boolCompare(a, b bool) int {
+	if a == b {
+		return 0
+	}
+	return 1
+}
+
+func compareBytesString(b []byte, s string) int {
+	n := min(len(b), len(s))
+	for i := 0; i < n; i++ {
+		if b[i] != s[i] {
+			if b[i] < s[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return len(b) - len(s)
+}
+
+// --- Parsing ---
+
+type filterTokKind uint8
+
+const (
+	tokEOF filterTokKind = iota
+	tokIdent
+	tokString
+	tokOp
+	tokAnd
+	tokOr
+	tokNot
+	tokLParen
+	tokRParen
+)
+
+type filterTok struct {
+	kind filterTokKind
+	text string
+	op   filterOp
+	pos  int
+}
+
+func (t filterTok) String() string {
+	if t.kind == tokEOF {
+		return "end of expression"
+	}
+	return strconv.Quote(t.text)
+}
+
+type filterParser struct {
+	src string
+	pos int
+	tok filterTok
+	err error
+}
+
+func (p *filterParser) next() {
+	for p.pos < len(p.src) && (p.src[p.pos] == ' ' || p.src[p.pos] == '\t' || p.src[p.pos] == '\n') {
+		p.pos++
+	}
+	start := p.pos
+	if p.pos >= len(p.src) {
+		p.tok = filterTok{kind: tokEOF, pos: start}
+		return
+	}
+
+	two := ""
+	if p.pos+1 < len(p.src) {
+		two = p.src[p.pos : p.pos+2]
+	}
+	switch two {
+	case "&&":
+		p.pos += 2
+		p.tok = filterTok{kind: tokAnd, text: two, pos: start}
+		return
+	case "||":
+		p.pos += 2
+		p.tok = filterTok{kind: tokOr, text: two, pos: start}
+		return
+	}
+	for op, name := range filterOpNames {
+		if len(name) == 2 && two == name {
+			p.pos += 2
+			p.tok = filterTok{kind: tokOp, text: name, op: filterOp(op), pos: start}
+			return
+		}
+	}
+
+	switch c := p.src[p.pos]; c {
+	case '!':
+		p.pos++
+		p.tok = filterTok{kind: tokNot, text: "!", pos: start}
+	case '(':
+		p.pos++
+		p.tok = filterTok{kind: tokLParen, text: "(", pos: start}
+	case ')':
+		p.pos++
+		p.tok = filterTok{kind: tokRParen, text: ")", pos: start}
+	case '<', '>':
+		p.pos++
+		op := opLt
+		if c == '>' {
+			op = opGt
+		}
+		p.tok = filterTok{kind: tokOp, text: string(c), op: op, pos: start}
+	case '"', '`':
+		end := p.pos + 1
+		for end < len(p.src) && p.src[end] != c {
+			if p.src[end] == '\\' && c == '"' {
+				end++
+			}
+			end++
+		}
+		if end >= len(p.src) {
+			p.fail(start, "unterminated string")
+			return
+		}
+		s, err := strconv.Unquote(p.src[p.pos : end+1])
+		if err != nil {
+			p.fail(start, "invalid string %s", p.src[p.pos:end+1])
+			return
+		}
+		p.pos = end + 1
+		p.tok = filterTok{kind: tokString, text: s, pos: start}
+	default:
+		for p.pos < len(p.src) && isFilterIdentByte(p.src[p.pos]) {
+			p.pos++
+		}
+		if p.pos == start {
+			r, _ := utf8.DecodeRuneInString(p.src[p.pos:])
+			p.fail(start, "unexpected character %q", r)
+			return
+		}
+		p.tok = filterTok{kind: tokIdent, text: p.src[start:p.pos], pos: start}
+	}
+}
+
+func isFilterIdentByte(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' ||
+		c == '_' || c == '.' || c == '-' || c == '/' || c == '+' || c >= 0x80
+}
+
+// fail records the first error and stops tokenizing.
+func (p *filterParser) fail(pos int, format string, args ...interface{}) {
+	if p.err == nil {
+		p.err = fmt.Errorf("offset %d: "+format, append([]interface{}{pos}, args...)...)
+	}
+	p.pos = len(p.src)
+	p.tok = filterTok{kind: tokEOF, pos: pos}
+}
+
+func (p *filterParser) parseOr() (filterNode, error) {
+	l, err := p.parseAnd()
+	for err == nil && p.tok.kind == tokOr {
+		p.next()
+		var r filterNode
+		if r, err = p.parseAnd(); err == nil {
+			l = &filterOr{l: l, r: r}
+		}
+	}
+	return l, err
+}
+
+func (p *filterParser) parseAnd() (filterNode, error) {
+	l, err := p.parseUnary()
+	for err == nil && p.tok.kind == tokAnd {
+		p.next()
+		var r filterNode
+		if r, err = p.parseUnary(); err == nil {
+			l = &filterAnd{l: l, r: r}
+		}
+	}
+	return l, err
+}
+
+func (p *filterParser) parseUnary() (filterNode, error) {
+	if p.tok.kind == tokNot {
+		p.next()
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &filterNot{x: x}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *filterParser) parsePrimary() (filterNode, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	switch p.tok.kind {
+	case tokLParen:
+		p.next()
+		x, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.tok.kind != tokRParen {
+			return nil, p.unexpected("\")\"")
+		}
+		p.next()
+		return x, nil
+	case tokIdent:
+	default:
+		return nil, p.unexpected("a name")
+	}
+
+	name := p.tok.text
+	p.next()
+	if p.err != nil {
+		return nil, p.err
+	}
+	if p.tok.kind != tokOp {
+		switch name {
+		case "true":
+			return filterConst(true), nil
+		case "false":
+			return filterConst(false), nil
+		}
+		return &filterPresent{key: name}, nil
+	}
+
+	op := p.tok.op
+	p.next()
+	if p.err != nil {
+		return nil, p.err
+	}
+	if p.tok.kind != tokIdent && p.tok.kind != tokString {
+		return nil, p.unexpected("a value")
+	}
+	lit := p.tok
+	p.next()
+
+	if name == "level" {
+		if op >= opMatch {
+			return nil, fmt.Errorf("offset %d: level does not support %s", lit.pos, filterOpNames[op])
+		}
+		var lvl Level
+		if err := lvl.UnmarshalText([]byte(lit.text)); err != nil {
+			return nil, fmt.Errorf("offset %d: %w", lit.pos, err)
+		}
+		return &filterLevel{op: op, lvl: lvl}, p.err
+	}
+
+	n := &filterCompare{op: op, key: name}
+	switch name {
+	case "msg", "message":
+		n.target = targetMessage
+	case "caller":
+		n.target = targetCaller
+	case "logger":
+		n.target = targetLogger
+	}
+
+	n.val.str = lit.text
+	if op >= opMatch {
+		re, err := regexp.Compile(lit.text)
+		if err != nil {
+			return nil, fmt.Errorf("offset %d: %w", lit.pos, err)
+		}
+		n.val.re = re
+		return n, p.err
+	}
+	if lit.kind == tokIdent {
+		if v, err := strconv.ParseFloat(lit.text, 64); err == nil {
+			n.val.num, n.val.isNum = v, true
+		} else if d, err := time.ParseDuration(lit.text); err == nil {
+			n.val.num, n.val.isNum = float64(d), true
+		} else if b, err := strconv.ParseBool(lit.text); err == nil && (lit.text == "true" || lit.text == "false") {
+			n.val.boolean, n.val.isBool = b, true
+		}
+	}
+	return n, p.err
+}
+
+func (p *filterParser) unexpected(want string) error {
+	if p.err != nil {
+		return p.err
+	}
+	return fmt.Errorf("offset %d: expected %s, found %s", p.tok.pos, want, p.tok)
+}
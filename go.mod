@@ -1,3 +1,5 @@
 module github.com/Bhavyyadav25/loghq
 
 go 1.25.6
+
+require golang.org/x/sys v0.33.0
@@ -0,0 +1,146 @@
+package loghq_test
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Bhavyyadav25/loghq"
+	"github.com/Bhavyyadav25/loghq/loghqtest"
+)
+
+// Run "go test -run TestGolden -update" to rewrite testdata/*.golden after
+// an intended formatting change, and review the diff.
+var update = flag.Bool("update", false, "rewrite golden files")
+
+type goldenWriter struct{ bytes.Buffer }
+
+func (w *goldenWriter) Sync() error { return nil }
+
+// goldenError carries log fields and a fixed stack, so structured error
+// output is reproducible.
+type goldenError struct{ table string }
+
+func (e *goldenError) Error() string { return "query failed" }
+func (e *goldenError) LogFields() []loghq.Field {
+	return []loghq.Field{loghq.String("table", e.table)}
+}
+func (e *goldenError) Frames() []runtime.Frame {
+	return []runtime.Frame{{Function: "app.(*Store).Save", File: "/src/app/store.go", Line: 42}}
+}
+
+type goldenUser struct {
+	Name  string   `json:"name"`
+	Roles []string `json:"roles"`
+}
+
+// logGoldenScenario exercises every level and field type. Caller lines
+// point into this function, so editing it means regenerating the golden
+// files.
+func logGoldenScenario(logger *loghq.Logger) {
+	logger.Trace("trace message")
+	logger.Debug("debug message", "attempt", 3, "ratio", 0.25, "ok", true)
+	logger.Info("server started", "port", 8080, "addr", "0.0.0.0")
+	logger.Success("deployed", "version", "1.2.3", "elapsed", 1500*time.Millisecond)
+	logger.Warn("needs quoting", "path", "/a b/c", "quote", `say "hi"`, "newline", "line1\nline2", "tab", "a\tb", "empty", "")
+	logger.Error("request failed", "error", errors.New("connection refused"), "status", uint(503))
+
+	logger.With(loghq.String("service", "api")).Named("payments").Info("charged",
+		"amount", 12.5,
+		"at", time.Date(2025, 1, 30, 9, 0, 0, 0, time.UTC),
+		"raw", []byte{0xde, 0xad, 0xbe, 0xef},
+		"user", goldenUser{Name: "ana", Roles: []string{"admin", "ops"}},
+		"unicode", "héllo ✓",
+	)
+	logger.With(
+		loghq.Strings("tags", []string{"a", "b c"}),
+		loghq.Ints("codes", []int{1, 2}),
+		loghq.Group("req", loghq.String("method", "GET"), loghq.Int("status", 200)),
+		loghq.Int64("neg", -7),
+		loghq.Float64("nan", math.NaN()),
+	).Info("typed fields")
+	logger.WithGroup("http").Info("grouped", "method", "POST", "status", 201)
+	logger.Error("save failed", "error", fmt.Errorf("save order: %w",
+		errors.Join(&goldenError{table: "orders"}, errors.New("timeout"))))
+}
+
+func TestGolden(t *testing.T) {
+	start := time.Date(2025, 1, 30, 14, 32, 1, 0, time.UTC)
+	tests := []struct {
+		name    string
+		handler func(w loghq.WriteSyncer) loghq.Handler
+	}{
+		{"console", func(w loghq.WriteSyncer) loghq.Handler {
+			return loghq.NewConsoleHandler(loghq.WithConsoleWriter(w), loghq.WithConsoleNoColor(), loghq.WithConsoleLevel(loghq.TraceLevel))
+		}},
+		{"console_color", func(w loghq.WriteSyncer) loghq.Handler {
+			return loghq.NewConsoleHandler(loghq.WithConsoleWriter(w), loghq.WithConsoleLevel(loghq.TraceLevel))
+		}},
+		{"json", func(w loghq.WriteSyncer) loghq.Handler {
+			return loghq.NewJSONHandler(w, loghq.WithJSONLevel(loghq.TraceLevel))
+		}},
+		{"logfmt", func(w loghq.WriteSyncer) loghq.Handler {
+			return loghq.NewLogfmtHandler(w, loghq.WithLogfmtLevel(loghq.TraceLevel))
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &goldenWriter{}
+			logger := loghq.New(
+				loghq.WithHandler(tt.handler(w)),
+				loghq.WithLevel(loghq.TraceLevel),
+				loghq.WithClock(loghqtest.NewSteppingClock(start, 1500*time.Microsecond)),
+				// Stacks hold absolute paths; caller lines are enough.
+				loghq.WithStackLevel(loghq.FatalLevel+1),
+			)
+			logGoldenScenario(logger)
+
+			path := filepath.Join("testdata", tt.name+".golden")
+			if *update {
+				if err := os.MkdirAll("testdata", 0o755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, w.Bytes(), 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			want, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("%v (run with -update to create it)", err)
+			}
+			if got := w.String(); got != string(want) {
+				t.Errorf("output differs from %s (run with -update if intended)\n%s", path, lineDiff(string(want), got))
+			}
+		})
+	}
+}
+
+// lineDiff lists the lines that differ between want and got.
+func lineDiff(want, got string) string {
+	wl := strings.Split(want, "\n")
+	gl := strings.Split(got, "\n")
+	var b strings.Builder
+	for i := 0; i < len(wl) || i < len(gl); i++ {
+		var w, g string
+		if i < len(wl) {
+			w = wl[i]
+		}
+		if i < len(gl) {
+			g = gl[i]
+		}
+		if w != g {
+			fmt.Fprintf(&b, "line %d:\n  want: %q\n  got:  %q\n", i+1, w, g)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,19 @@
+module github.com/Bhavyyadav25/loghq/grpclog
+
+go 1.25.6
+
+// Builds inside this repository use the loghq checkout next to it.
+replace github.com/Bhavyyadav25/loghq => ../
+
+require (
+	github.com/Bhavyyadav25/loghq v0.0.0-20261016075236-ab367bfaf973
+	google.golang.org/grpc v1.75.1
+)
+
+require (
+	golang.org/x/net v0.41.0 // indirect
+	golang.org/x/sys v0.33.0 // indirect
+	golang.org/x/text v0.26.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20250707201910-8d1bb00bc6a7 // indirect
+	google.golang.org/protobuf v1.36.6 // indirect
+)
@@ -0,0 +1,354 @@
+// Package grpclog provides gRPC interceptors that log one structured
+// record per call through loghq.
+//
+//	srv := grpc.NewServer(
+//		grpc.ChainUnaryInterceptor(grpclog.UnaryServerInterceptor(logger)),
+//		grpc.ChainStreamInterceptor(grpclog.StreamServerInterceptor(logger)),
+//	)
+//
+// Server interceptors give each call a request ID, taken from the
+// x-request-id metadata when the client sends a valid one and generated
+// otherwise, which is returned in the response header. Handlers reach the
+// request-scoped logger, already carrying request_id and method, through
+// the call context:
+//
+//	func (s *server) GetOrder(ctx context.Context, req *pb.GetOrderRequest) (*pb.Order, error) {
+//		loghq.InfoContext(ctx, "loading order")
+//		...
+//	}
+//
+// grpclog is a separate module so that the root loghq module does not
+// depend on gRPC.
+package grpclog
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Bhavyyadav25/loghq"
+	"github.com/Bhavyyadav25/loghq/internal/requestid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultRequestIDKey is the metadata key used to receive and return
+// request IDs.
+const DefaultRequestIDKey = "x-request-id"
+
+// Option configures an interceptor.
+type Option func(*config)
+
+type config struct {
+	key       string
+	generate  func() string
+	trustMD   bool
+	skip      func(fullMethod string) bool
+	codeLevel func(codes.Code) loghq.Level
+}
+
+func newConfig(opts []Option) *config {
+	cfg := &config{
+		key:       DefaultRequestIDKey,
+		generate:  rand.Text,
+		trustMD:   true,
+		codeLevel: CodeLevel,
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return cfg
+}
+
+// WithRequestIDKey sets the request ID metadata key. Default:
+// DefaultRequestIDKey.
+func WithRequestIDKey(key string) Option {
+	return func(c *config) { c.key = key }
+}
+
+// WithRequestIDGenerator sets the function that creates request IDs.
+// Default: 26 random base32 characters.
+func WithRequestIDGenerator(fn func() string) Option {
+	return func(c *config) { c.generate = fn }
+}
+
+// WithTrustRequestID controls whether an incoming request ID is
+// propagated. Default: true.
+func WithTrustRequestID(on bool) Option {
+	return func(c *config) { c.trustMD = on }
+}
+
+// WithSkip suppresses the call record for methods where fn returns true,
+// such as "/grpc.health.v1.Health/Check". Server calls still get an ID and
+// a logger.
+func WithSkip(fn func(fullMethod string) bool) Option {
+	return func(c *config) { c.skip = fn }
+}
+
+// WithCodeLevel sets how a status code maps to the call record's level.
+// Default: CodeLevel.
+func WithCodeLevel(fn func(codes.Code) loghq.Level) Option {
+	return func(c *config) { c.codeLevel = fn }
+}
+
+// CodeLevel is the default code to level mapping: OK is Success; codes
+// caused by the caller, such as NotFound or InvalidArgument, are Info;
+// codes that suggest trouble worth a look, such as DeadlineExceeded or
+// Unavailable, are Warn; and server faults, such as Internal or Unknown,
+// are Error.
+func CodeLevel(code codes.Code) loghq.Level {
+	switch code {
+	case codes.OK:
+		return loghq.SuccessLevel
+	case codes.Canceled, codes.InvalidArgument, codes.NotFound, codes.AlreadyExists,
+		codes.Unauthenticated:
+		return loghq.InfoLevel
+	case codes.DeadlineExceeded, codes.PermissionDenied, codes.ResourceExhausted,
+		codes.FailedPrecondition, codes.Aborted, codes.OutOfRange, codes.Unavailable:
+		return loghq.WarnLevel
+	default:
+		// Unknown, Unimplemented, Internal, DataLoss.
+		return loghq.ErrorLevel
+	}
+}
+
+type ctxRequestIDKey struct{}
+
+// RequestID returns the ID assigned to the call by a server interceptor,
+// or "" outside one.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(ctxRequestIDKey{}).(string)
+	return id
+}
+
+// callLogger returns the logger for call records. They always come from
+// this package and are not program errors, so caller and stack would
+// only add noise.
+func callLogger(logger *loghq.Logger) *loghq.Logger {
+	return logger.WithOptions(loghq.WithCaller(false), loghq.WithStackLevel(loghq.FatalLevel+1))
+}
+
+// UnaryServerInterceptor returns an interceptor that attaches a
+// request-scoped logger to the call context and logs method, peer, code,
+// and duration.
+func UnaryServerInterceptor(logger *loghq.Logger, opts ...Option) grpc.UnaryServerInterceptor {
+	cfg := newConfig(opts)
+	calls := callLogger(logger)
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		ctx, fields := cfg.serverContext(ctx, logger, info.FullMethod)
+		resp, err := handler(ctx, req)
+		if cfg.skip == nil || !cfg.skip(info.FullMethod) {
+			cfg.logCall(ctx, calls, fields, err, start, nil)
+		}
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor returns an interceptor that attaches a
+// request-scoped logger to the stream context and logs method, peer,
+// code, duration, and message counts.
+func StreamServerInterceptor(logger *loghq.Logger, opts ...Option) grpc.StreamServerInterceptor {
+	cfg := newConfig(opts)
+	calls := callLogger(logger)
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		ctx, fields := cfg.serverContext(ss.Context(), logger, info.FullMethod)
+		ws := &serverStream{ServerStream: ss, ctx: ctx}
+		err := handler(srv, ws)
+		if cfg.skip == nil || !cfg.skip(info.FullMethod) {
+			cfg.logCall(ctx, calls, fields, err, start, &ws.counts)
+		}
+		return err
+	}
+}
+
+// UnaryClientInterceptor returns an interceptor that logs method, target,
+// code, and duration of outgoing calls. Fields attached to the call
+// context with loghq.ContextWithFields are included.
+func UnaryClientInterceptor(logger *loghq.Logger, opts ...Option) grpc.UnaryClientInterceptor {
+	cfg := newConfig(opts)
+	calls := callLogger(logger)
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, callOpts ...grpc.CallOption) error {
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, callOpts...)
+		if cfg.skip == nil || !cfg.skip(method) {
+			cfg.logCall(ctx, calls, clientFields(method, cc), err, start, nil)
+		}
+		return err
+	}
+}
+
+// StreamClientInterceptor returns an interceptor that logs method, target,
+// code, duration, and message counts of outgoing streams. The record is
+// written when the stream ends: when RecvMsg returns an error (io.EOF for
+// success) or the stream could not be created.
+func StreamClientInterceptor(logger *loghq.Logger, opts ...Option) grpc.StreamClientInterceptor {
+	cfg := newConfig(opts)
+	calls := callLogger(logger)
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, callOpts ...grpc.CallOption) (grpc.ClientStream, error) {
+		start := time.Now()
+		skip := cfg.skip != nil && cfg.skip(method)
+		cs, err := streamer(ctx, desc, cc, method, callOpts...)
+		if err != nil {
+			if !skip {
+				cfg.logCall(ctx, calls, clientFields(method, cc), err, start, nil)
+			}
+			return cs, err
+		}
+		if skip {
+			return cs, nil
+		}
+		wc := &clientStream{ClientStream: cs, serverStreams: desc.ServerStreams}
+		wc.finish = func(err error) {
+			cfg.logCall(ctx, calls, clientFields(method, cc), err, start, &wc.counts)
+		}
+		return wc, nil
+	}
+}
+
+// serverContext assigns the request ID, returns it in the response
+// header, and stores the request-scoped logger. The returned fields start
+// every call record.
+func (cfg *config) serverContext(ctx context.Context, logger *loghq.Logger, method string) (context.Context, []loghq.Field) {
+	id := ""
+	if cfg.trustMD {
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if v := md.Get(cfg.key); len(v) > 0 {
+				id = v[0]
+			}
+		}
+	}
+	if !requestid.Valid(id) {
+		id = cfg.generate()
+	}
+	// Fails only if headers were already sent, which cannot happen before
+	// the handler runs.
+	_ = grpc.SetHeader(ctx, metadata.Pairs(cfg.key, id))
+
+	fields := []loghq.Field{
+		loghq.String("request_id", id),
+		loghq.String("method", method),
+	}
+	ctx = context.WithValue(ctx, ctxRequestIDKey{}, id)
+	ctx = loghq.IntoContext(ctx, logger.With(fields...))
+
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		fields = append(fields, loghq.String("peer", p.Addr.String()))
+	}
+	return ctx, fields
+}
+
+func clientFields(method string, cc *grpc.ClientConn) []loghq.Field {
+	return []loghq.Field{
+		loghq.String("method", method),
+		loghq.String("target", cc.Target()),
+	}
+}
+
+// logCall writes the call record. counts is nil for unary calls.
+func (cfg *config) logCall(ctx context.Context, calls *loghq.Logger, fields []loghq.Field, err error, start time.Time, counts *msgCounts) {
+	code := status.Code(err)
+	lvl := cfg.codeLevel(code)
+	if !calls.Enabled(lvl) {
+		return
+	}
+	fields = append(fields,
+		loghq.String("code", code.String()),
+		loghq.Duration("duration", time.Since(start)),
+	)
+	if counts != nil {
+		fields = append(fields,
+			loghq.Int64("msgs_sent", counts.sent.Load()),
+			loghq.Int64("msgs_received", counts.received.Load()),
+		)
+	}
+	if err != nil {
+		fields = append(fields, loghq.Err(err))
+	}
+	// Never Panic or Fatal: a call record must not stop the process.
+	calls.With(fields...).Log(ctx, min(lvl, loghq.ErrorLevel), "call")
+}
+
+type msgCounts struct {
+	sent     atomic.Int64
+	received atomic.Int64
+}
+
+// serverStream carries the request-scoped context and counts messages.
+type serverStream struct {
+	grpc.ServerStream
+	ctx    context.Context
+	counts msgCounts
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *serverStream) SendMsg(m any) error {
+	err := s.ServerStream.SendMsg(m)
+	if err == nil {
+		s.counts.sent.Add(1)
+	}
+	return err
+}
+
+func (s *serverStream) RecvMsg(m any) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil {
+		s.counts.received.Add(1)
+	}
+	return err
+}
+
+// clientStream counts messages and calls finish once when the stream
+// ends.
+type clientStream struct {
+	grpc.ClientStream
+	serverStreams bool
+	counts        msgCounts
+	once          sync.Once
+	finish        func(error)
+}
+
+func (s *clientStream) SendMsg(m any) error {
+	err := s.ClientStream.SendMsg(m)
+	if err == nil {
+		s.counts.sent.Add(1)
+	} else if !errors.Is(err, io.EOF) {
+		// io.EOF means the stream was ended by the server; the status
+		// arrives from RecvMsg.
+		s.done(err)
+	}
+	return err
+}
+
+func (s *clientStream) RecvMsg(m any) error {
+	err := s.ClientStream.RecvMsg(m)
+	switch {
+	case err == nil:
+		s.counts.received.Add(1)
+		if !s.serverStreams {
+			// A single response ends the call; gRPC checks for the
+			// trailing EOF internally.
+			s.done(nil)
+		}
+	case errors.Is(err, io.EOF):
+		s.done(nil)
+	default:
+		s.done(err)
+	}
+	return err
+}
+
+func (s *clientStream) done(err error) {
+	s.once.Do(func() { s.finish(err) })
+}
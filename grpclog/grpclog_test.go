@@ -0,0 +1,280 @@
+package grpclog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Bhavyyadav25/loghq"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type testWriter struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (w *testWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.Write(p)
+}
+func (w *testWriter) Sync() error { return nil }
+
+func (w *testWriter) reset() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.buf.Reset()
+}
+
+// records decodes the JSON lines written so far.
+func (w *testWriter) records(t *testing.T) []map[string]interface{} {
+	t.Helper()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var out []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(w.buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var m map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &m); err != nil {
+			t.Fatalf("bad JSON %q: %v", line, err)
+		}
+		out = append(out, m)
+	}
+	return out
+}
+
+func newLogger(w *testWriter) *loghq.Logger {
+	return loghq.New(loghq.WithHandler(loghq.NewJSONHandler(w)), loghq.WithLevel(loghq.TraceLevel))
+}
+
+// healthServer answers Check by service name and streams three updates
+// from Watch, logging through the request-scoped logger.
+type healthServer struct {
+	healthpb.UnimplementedHealthServer
+}
+
+func (healthServer) Check(ctx context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	loghq.InfoContext(ctx, "checking", "service", req.Service)
+	switch req.Service {
+	case "fail":
+		return nil, status.Error(codes.Internal, "boom")
+	case "missing":
+		return nil, status.Error(codes.NotFound, "no such service")
+	}
+	return &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING}, nil
+}
+
+func (healthServer) Watch(req *healthpb.HealthCheckRequest, stream grpc.ServerStreamingServer[healthpb.HealthCheckResponse]) error {
+	loghq.InfoContext(stream.Context(), "watching")
+	for i := 0; i < 3; i++ {
+		if err := stream.Send(&healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// start serves healthServer over bufconn with server interceptors logging
+// to sw and returns a client whose interceptors log to cw.
+func start(t *testing.T, sw, cw *testWriter, opts ...Option) healthpb.HealthClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(UnaryServerInterceptor(newLogger(sw), opts...)),
+		grpc.ChainStreamInterceptor(StreamServerInterceptor(newLogger(sw), opts...)),
+	)
+	healthpb.RegisterHealthServer(srv, healthServer{})
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithChainUnaryInterceptor(UnaryClientInterceptor(newLogger(cw), opts...)),
+		grpc.WithChainStreamInterceptor(StreamClientInterceptor(newLogger(cw), opts...)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return healthpb.NewHealthClient(conn)
+}
+
+const checkMethod = "/grpc.health.v1.Health/Check"
+
+func TestUnaryInterceptors(t *testing.T) {
+	sw, cw := &testWriter{}, &testWriter{}
+	client := start(t, sw, cw)
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), DefaultRequestIDKey, "abc-123")
+	ctx = loghq.ContextWithFields(ctx, loghq.String("job", "sync"))
+	var header metadata.MD
+	if _, err := client.Check(ctx, &healthpb.HealthCheckRequest{}, grpc.Header(&header)); err != nil {
+		t.Fatal(err)
+	}
+	if got := header.Get(DefaultRequestIDKey); len(got) != 1 || got[0] != "abc-123" {
+		t.Errorf("response header request ID = %v", got)
+	}
+
+	recs := sw.records(t)
+	if len(recs) != 2 {
+		t.Fatalf("server records: %v", recs)
+	}
+	if recs[0]["msg"] != "checking" || recs[0]["request_id"] != "abc-123" || recs[0]["method"] != checkMethod {
+		t.Errorf("handler record: %v", recs[0])
+	}
+	call := recs[1]
+	want := map[string]interface{}{
+		"msg":        "call",
+		"level":      "OK",
+		"request_id": "abc-123",
+		"method":     checkMethod,
+		"code":       "OK",
+	}
+	for k, v := range want {
+		if call[k] != v {
+			t.Errorf("server call %s = %v, want %v", k, call[k], v)
+		}
+	}
+	if p, _ := call["peer"].(string); p == "" {
+		t.Errorf("server call has no peer: %v", call)
+	}
+	for _, k := range []string{"duration"} {
+		if _, ok := call[k]; !ok {
+			t.Errorf("server call has no %s", k)
+		}
+	}
+	for _, k := range []string{"caller", "msgs_sent", "error"} {
+		if _, ok := call[k]; ok {
+			t.Errorf("server call has %s: %v", k, call)
+		}
+	}
+
+	crecs := cw.records(t)
+	if len(crecs) != 1 {
+		t.Fatalf("client records: %v", crecs)
+	}
+	if c := crecs[0]; c["method"] != checkMethod || c["target"] != "passthrough:///bufnet" || c["code"] != "OK" || c["job"] != "sync" {
+		t.Errorf("client call: %v", c)
+	}
+}
+
+func TestUnaryErrorCodes(t *testing.T) {
+	sw, cw := &testWriter{}, &testWriter{}
+	client := start(t, sw, cw, WithRequestIDGenerator(func() string { return "gen-1" }))
+
+	for _, tt := range []struct {
+		service, code, level string
+	}{
+		{"fail", "Internal", "ERROR"},
+		{"missing", "NotFound", "INFO"},
+	} {
+		sw.reset()
+		cw.reset()
+		_, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: tt.service})
+		if status.Code(err).String() != tt.code {
+			t.Fatalf("%s: err = %v", tt.service, err)
+		}
+		recs := sw.records(t)
+		call := recs[len(recs)-1]
+		if call["code"] != tt.code || call["level"] != tt.level || call["request_id"] != "gen-1" {
+			t.Errorf("%s: server call %v", tt.service, call)
+		}
+		if e, _ := call["error"].(string); !strings.Contains(e, "code = "+tt.code) {
+			t.Errorf("%s: error field %q", tt.service, e)
+		}
+		if _, ok := call["stack"]; ok {
+			t.Errorf("%s: call record has a stack", tt.service)
+		}
+		if c := cw.records(t)[0]; c["code"] != tt.code || c["level"] != tt.level {
+			t.Errorf("%s: client call %v", tt.service, c)
+		}
+	}
+}
+
+func TestStreamInterceptors(t *testing.T) {
+	sw, cw := &testWriter{}, &testWriter{}
+	client := start(t, sw, cw)
+
+	stream, err := client.Watch(context.Background(), &healthpb.HealthCheckRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := 0
+	for {
+		if _, err := stream.Recv(); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		n++
+	}
+	if n != 3 {
+		t.Fatalf("received %d messages", n)
+	}
+
+	recs := sw.records(t)
+	if len(recs) != 2 || recs[0]["msg"] != "watching" || recs[0]["request_id"] == nil {
+		t.Fatalf("server records: %v", recs)
+	}
+	call := recs[1]
+	if call["code"] != "OK" || call["msgs_sent"] != float64(3) || call["msgs_received"] != float64(1) {
+		t.Errorf("server stream call: %v", call)
+	}
+
+	crecs := cw.records(t)
+	if len(crecs) != 1 {
+		t.Fatalf("client records: %v", crecs)
+	}
+	if c := crecs[0]; c["code"] != "OK" || c["msgs_sent"] != float64(1) || c["msgs_received"] != float64(3) {
+		t.Errorf("client stream call: %v", c)
+	}
+}
+
+func TestSkip(t *testing.T) {
+	sw, cw := &testWriter{}, &testWriter{}
+	client := start(t, sw, cw, WithSkip(func(method string) bool { return method == checkMethod }))
+
+	if _, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	// The handler still logs through the request-scoped logger.
+	recs := sw.records(t)
+	if len(recs) != 1 || recs[0]["msg"] != "checking" || recs[0]["request_id"] == nil {
+		t.Errorf("server records: %v", recs)
+	}
+	if recs := cw.records(t); len(recs) != 0 {
+		t.Errorf("client records: %v", recs)
+	}
+}
+
+func TestCodeLevel(t *testing.T) {
+	for code, want := range map[codes.Code]loghq.Level{
+		codes.OK:               loghq.SuccessLevel,
+		codes.NotFound:         loghq.InfoLevel,
+		codes.DeadlineExceeded: loghq.WarnLevel,
+		codes.Unavailable:      loghq.WarnLevel,
+		codes.Internal:         loghq.ErrorLevel,
+		codes.Unknown:          loghq.ErrorLevel,
+	} {
+		if got := CodeLevel(code); got != want {
+			t.Errorf("CodeLevel(%v) = %v, want %v", code, got, want)
+		}
+	}
+}
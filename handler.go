@@ -1,7 +1,5 @@
 package loghq
 
-import "sync/atomic"
-
 // Handler processes log records. Minimal interface per ISP —
 // only the two methods every handler must have.
 type Handler interface {
@@ -19,24 +17,43 @@ type Closer interface {
 	Close() error
 }
 
+// flushHandler flushes h if it implements Flusher.
+func flushHandler(h Handler) error {
+	if f, ok := h.(Flusher); ok {
+		return f.Flush()
+	}
+	return nil
+}
+
+// closeHandler closes h if it implements Closer.
+func closeHandler(h Handler) error {
+	if c, ok := h.(Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
 // BaseHandler composes an Encoder, WriteSyncer, and level filter.
 // Concrete handlers embed this to eliminate boilerplate.
 type BaseHandler struct {
 	enc    Encoder
 	writer WriteSyncer
-	level  atomic.Int32
+	level  *LevelVar
 }
 
 // NewBaseHandler creates a handler with the given encoder, writer, and level.
 func NewBaseHandler(enc Encoder, w WriteSyncer, lvl Level) *BaseHandler {
-	h := &BaseHandler{enc: enc, writer: w}
-	h.level.Store(int32(lvl))
-	return h
+	return NewBaseHandlerVar(enc, w, NewLevelVar(lvl))
+}
+
+// NewBaseHandlerVar creates a handler whose level is read from v.
+func NewBaseHandlerVar(enc Encoder, w WriteSyncer, v *LevelVar) *BaseHandler {
+	return &BaseHandler{enc: enc, writer: w, level: v}
 }
 
 // Enabled returns true if the level passes the filter.
 func (h *BaseHandler) Enabled(lvl Level) bool {
-	return lvl >= Level(h.level.Load())
+	return lvl >= h.level.Level()
 }
 
 // Handle encodes the record and writes it. Buffer is pooled for zero-alloc.
@@ -60,5 +77,10 @@ func (h *BaseHandler) Close() error {
 
 // SetLevel changes the handler's level atomically.
 func (h *BaseHandler) SetLevel(lvl Level) {
-	h.level.Store(int32(lvl))
+	h.level.Set(lvl)
+}
+
+// LevelVar returns the handler's level variable.
+func (h *BaseHandler) LevelVar() *LevelVar {
+	return h.level
 }
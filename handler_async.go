@@ -0,0 +1,228 @@
+package loghq
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrHandlerClosed is returned by Handle after the handler has been closed.
+var ErrHandlerClosed = errors.New("loghq: handler closed")
+
+// OverflowPolicy decides what AsyncHandler does when its queue is full.
+type OverflowPolicy uint8
+
+const (
+	// OverflowBlock makes Handle wait for free space. Nothing is lost.
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDropNewest discards the incoming record.
+	OverflowDropNewest
+	// OverflowDropOldest evicts the oldest queued record to make room.
+	OverflowDropOldest
+	// OverflowDropBelowLevel discards incoming records below the configured
+	// level and blocks for the rest, so errors are never lost.
+	OverflowDropBelowLevel
+)
+
+const defaultAsyncQueueSize = 1024
+
+// AsyncHandler decouples callers from a slow Handler. Records are copied
+// into a fixed-size ring buffer and written by a single background goroutine.
+type AsyncHandler struct {
+	handler   Handler
+	policy    OverflowPolicy
+	dropBelow Level
+	onError   func(error)
+
+	mu       sync.Mutex
+	notEmpty sync.Cond
+	notFull  sync.Cond
+	idle     sync.Cond
+	ring     []Record
+	head     int
+	count    int
+	busy     bool
+	closed   bool
+
+	dropped atomic.Uint64
+	done    chan struct{}
+}
+
+type asyncConfig struct {
+	size      int
+	policy    OverflowPolicy
+	dropBelow Level
+	onError   func(error)
+}
+
+// AsyncOption configures an AsyncHandler.
+type AsyncOption func(*asyncConfig)
+
+// WithAsyncQueueSize sets the ring buffer capacity. Default: 1024.
+func WithAsyncQueueSize(n int) AsyncOption {
+	return func(c *asyncConfig) {
+		if n > 0 {
+			c.size = n
+		}
+	}
+}
+
+// WithAsyncOverflow sets the policy applied when the queue is full.
+func WithAsyncOverflow(p OverflowPolicy) AsyncOption {
+	return func(c *asyncConfig) { c.policy = p }
+}
+
+// WithAsyncDropBelow selects OverflowDropBelowLevel with the given level.
+func WithAsyncDropBelow(l Level) AsyncOption {
+	return func(c *asyncConfig) {
+		c.policy = OverflowDropBelowLevel
+		c.dropBelow = l
+	}
+}
+
+// WithAsyncErrorHandler sets a callback for errors returned by the wrapped
+// handler, which would otherwise be discarded by the background goroutine.
+func WithAsyncErrorHandler(fn func(error)) AsyncOption {
+	return func(c *asyncConfig) { c.onError = fn }
+}
+
+// NewAsyncHandler wraps h and starts the background writer.
+func NewAsyncHandler(h Handler, opts ...AsyncOption) *AsyncHandler {
+	cfg := &asyncConfig{size: defaultAsyncQueueSize}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	a := &AsyncHandler{
+		handler:   h,
+		policy:    cfg.policy,
+		dropBelow: cfg.dropBelow,
+		onError:   cfg.onError,
+		ring:      make([]Record, cfg.size),
+		done:      make(chan struct{}),
+	}
+	a.notEmpty.L = &a.mu
+	a.notFull.L = &a.mu
+	a.idle.L = &a.mu
+
+	go a.run()
+	return a
+}
+
+func (a *AsyncHandler) Enabled(lvl Level) bool {
+	return a.handler.Enabled(lvl)
+}
+
+// Handle copies rec into the queue. The caller keeps ownership of rec.
+func (a *AsyncHandler) Handle(rec *Record) error {
+	a.mu.Lock()
+	for a.count == len(a.ring) && !a.closed {
+		switch a.policy {
+		case OverflowDropNewest:
+			a.mu.Unlock()
+			a.dropped.Add(1)
+			return nil
+		case OverflowDropOldest:
+			a.head = (a.head + 1) % len(a.ring)
+			a.count--
+			a.dropped.Add(1)
+		case OverflowDropBelowLevel:
+			if rec.Level < a.dropBelow {
+				a.mu.Unlock()
+				a.dropped.Add(1)
+				return nil
+			}
+			a.notFull.Wait()
+		default:
+			a.notFull.Wait()
+		}
+	}
+	if a.closed {
+		a.mu.Unlock()
+		return ErrHandlerClosed
+	}
+
+	rec.copyTo(&a.ring[(a.head+a.count)%len(a.ring)])
+	a.count++
+	a.notEmpty.Signal()
+	a.mu.Unlock()
+	return nil
+}
+
+// run drains the queue until Close is called and the queue is empty.
+func (a *AsyncHandler) run() {
+	defer close(a.done)
+	var rec Record
+
+	a.mu.Lock()
+	for {
+		for a.count == 0 && !a.closed {
+			a.busy = false
+			a.idle.Broadcast()
+			a.notEmpty.Wait()
+		}
+		if a.count == 0 {
+			a.busy = false
+			a.idle.Broadcast()
+			a.mu.Unlock()
+			return
+		}
+
+		a.busy = true
+		a.ring[a.head].copyTo(&rec)
+		a.head = (a.head + 1) % len(a.ring)
+		a.count--
+		a.notFull.Signal()
+		a.mu.Unlock()
+
+		if err := a.handler.Handle(&rec); err != nil && a.onError != nil {
+			a.onError(err)
+		}
+
+		a.mu.Lock()
+	}
+}
+
+// Dropped returns the number of records discarded by the overflow policy.
+func (a *AsyncHandler) Dropped() uint64 {
+	return a.dropped.Load()
+}
+
+// Len returns the number of records waiting to be written.
+func (a *AsyncHandler) Len() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.count
+}
+
+// Flush blocks until every queued record has been handled, then flushes
+// the wrapped handler if it implements Flusher.
+func (a *AsyncHandler) Flush() error {
+	a.mu.Lock()
+	for (a.count > 0 || a.busy) && !a.closed {
+		a.idle.Wait()
+	}
+	a.mu.Unlock()
+
+	return flushHandler(a.handler)
+}
+
+// Close stops accepting records, drains the queue, and closes the wrapped
+// handler if it implements Closer. Blocked callers return ErrHandlerClosed.
+func (a *AsyncHandler) Close() error {
+	a.mu.Lock()
+	if a.closed {
+		a.mu.Unlock()
+		<-a.done
+		return nil
+	}
+	a.closed = true
+	a.notEmpty.Broadcast()
+	a.notFull.Broadcast()
+	a.idle.Broadcast()
+	a.mu.Unlock()
+
+	<-a.done
+
+	return closeHandler(a.handler)
+}
@@ -18,8 +18,11 @@ func NewConsoleHandler(opts ...ConsoleOption) *ConsoleHandler {
 	for _, opt := range opts {
 		opt(cfg)
 	}
+	if cfg.lvar == nil {
+		cfg.lvar = NewLevelVar(cfg.level)
+	}
 	return &ConsoleHandler{
-		BaseHandler: NewBaseHandler(cfg.enc, cfg.writer, cfg.level),
+		BaseHandler: NewBaseHandlerVar(cfg.enc, cfg.writer, cfg.lvar),
 	}
 }
 
@@ -28,6 +31,7 @@ type consoleConfig struct {
 	enc    *ConsoleEncoder
 	writer WriteSyncer
 	level  Level
+	lvar   *LevelVar
 }
 
 // ConsoleOption configures a ConsoleHandler.
@@ -53,6 +57,12 @@ func WithConsoleLevel(l Level) ConsoleOption {
 	return func(c *consoleConfig) { c.level = l }
 }
 
+// WithConsoleLevelVar reads the minimum level from a shared LevelVar,
+// overriding WithConsoleLevel.
+func WithConsoleLevelVar(v *LevelVar) ConsoleOption {
+	return func(c *consoleConfig) { c.lvar = v }
+}
+
 // WithConsoleStdout writes to stdout instead of stderr.
 func WithConsoleStdout() ConsoleOption {
 	return func(c *consoleConfig) { c.writer = &fileWriteSyncer{os.Stdout} }
@@ -0,0 +1,74 @@
+package loghq
+
+import "sync/atomic"
+
+// FilterHandler forwards only the records that satisfy a Filter. The
+// filter can be swapped at runtime, e.g. from a config reload or admin
+// endpoint, without rebuilding the handler chain:
+//
+//	h, err := loghq.NewFilterHandler(next, `level>=warn || path!="/healthz"`)
+//	...
+//	err = h.SetExpr(`level>=warn || (path!="/healthz" && path!="/metrics")`)
+//
+// A nil filter passes every record.
+type FilterHandler struct {
+	handler Handler
+	filter  atomic.Pointer[Filter]
+}
+
+// NewFilterHandler wraps h with a filter compiled from expr. An empty expr
+// passes every record.
+func NewFilterHandler(h Handler, expr string) (*FilterHandler, error) {
+	fh := &FilterHandler{handler: h}
+	if err := fh.SetExpr(expr); err != nil {
+		return nil, err
+	}
+	return fh, nil
+}
+
+// SetExpr compiles expr and installs it. On error the current filter is
+// kept. An empty expr removes the filter.
+func (fh *FilterHandler) SetExpr(expr string) error {
+	if expr == "" {
+		fh.filter.Store(nil)
+		return nil
+	}
+	f, err := CompileFilter(expr)
+	if err != nil {
+		return err
+	}
+	fh.filter.Store(f)
+	return nil
+}
+
+// SetFilter installs a precompiled filter, or removes it if f is nil.
+func (fh *FilterHandler) SetFilter(f *Filter) {
+	fh.filter.Store(f)
+}
+
+// Filter returns the current filter, or nil.
+func (fh *FilterHandler) Filter() *Filter {
+	return fh.filter.Load()
+}
+
+func (fh *FilterHandler) Enabled(lvl Level) bool {
+	return fh.handler.Enabled(lvl)
+}
+
+// Handle forwards rec if it matches the current filter.
+func (fh *FilterHandler) Handle(rec *Record) error {
+	if f := fh.filter.Load(); f != nil && !f.Match(rec) {
+		return nil
+	}
+	return fh.handler.Handle(rec)
+}
+
+// Flush flushes the wrapped handler if it implements Flusher.
+func (fh *FilterHandler) Flush() error {
+	return flushHandler(fh.handler)
+}
+
+// Close closes the wrapped handler if it implements Closer.
+func (fh *FilterHandler) Close() error {
+	return closeHandler(fh.handler)
+}
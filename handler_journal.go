@@ -0,0 +1,266 @@
+package loghq
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// DefaultJournalSocket is the systemd-journald native protocol socket.
+const DefaultJournalSocket = "/run/systemd/journal/socket"
+
+// JournalHandler sends records to systemd-journald using its native
+// protocol, so fields arrive as indexed journal fields rather than text:
+//
+//	journalctl SYSLOG_IDENTIFIER=api ORDER_ID=42
+//
+// Every record carries MESSAGE, PRIORITY (see SyslogSeverity),
+// SYSLOG_IDENTIFIER, and, when available, CODE_FILE, CODE_LINE, CODE_FUNC,
+// LOGGER (the Named logger), and STACKTRACE. CODE_FILE is the full source
+// path, as journald expects. Field keys are uppercased and sanitized to
+// journald's rules; groups and namespaces are joined with '_'. A field
+// whose name would clash with one of the names above is prefixed with
+// FIELD_, so a "message" field arrives as FIELD_MESSAGE. Records too large
+// for a datagram are passed as a sealed memfd.
+type JournalHandler struct {
+	level      *LevelVar
+	conn       *net.UnixConn
+	identifier string
+}
+
+// JournalOption configures a JournalHandler.
+type JournalOption func(*journalConfig)
+
+type journalConfig struct {
+	socket     string
+	level      Level
+	lvar       *LevelVar
+	identifier string
+}
+
+// WithJournalSocket sets the socket path. Default: DefaultJournalSocket.
+func WithJournalSocket(path string) JournalOption {
+	return func(c *journalConfig) { c.socket = path }
+}
+
+// WithJournalLevel sets the minimum level.
+func WithJournalLevel(l Level) JournalOption {
+	return func(c *journalConfig) { c.level = l }
+}
+
+// WithJournalLevelVar reads the minimum level from a shared LevelVar,
+// overriding WithJournalLevel.
+func WithJournalLevelVar(v *LevelVar) JournalOption {
+	return func(c *journalConfig) { c.lvar = v }
+}
+
+// WithJournalIdentifier sets SYSLOG_IDENTIFIER. Default: the program name.
+func WithJournalIdentifier(id string) JournalOption {
+	return func(c *journalConfig) { c.identifier = id }
+}
+
+// NewJournalHandler connects to the journald socket.
+func NewJournalHandler(opts ...JournalOption) (*JournalHandler, error) {
+	cfg := &journalConfig{
+		socket:     DefaultJournalSocket,
+		level:      TraceLevel,
+		identifier: filepath.Base(os.Args[0]),
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if cfg.lvar == nil {
+		cfg.lvar = NewLevelVar(cfg.level)
+	}
+
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: cfg.socket, Net: "unixgram"})
+	if err != nil {
+		return nil, fmt.Errorf("loghq: cannot connect to journal: %w", err)
+	}
+	return &JournalHandler{level: cfg.lvar, conn: conn, identifier: cfg.identifier}, nil
+}
+
+func (h *JournalHandler) Enabled(lvl Level) bool {
+	return lvl >= h.level.Level()
+}
+
+// Handle encodes the record in the native protocol and sends it as one
+// datagram, falling back to a memfd when it is too large.
+func (h *JournalHandler) Handle(rec *Record) error {
+	buf := getBuffer()
+	val := getBuffer()
+	defer putBuffer(buf)
+	defer putBuffer(val)
+
+	appendJournalString(buf, "MESSAGE", rec.Message)
+	buf.AppendString("PRIORITY=")
+	buf.AppendInt(int64(SyslogSeverity(rec.Level)))
+	buf.AppendByte('\n')
+	if h.identifier != "" {
+		appendJournalString(buf, "SYSLOG_IDENTIFIER", h.identifier)
+	}
+	if rec.Name != "" {
+		appendJournalString(buf, "LOGGER", rec.Name)
+	}
+	if rec.Caller.Defined() {
+		appendJournalString(buf, "CODE_FILE", journalCodeFile(rec.Caller))
+		buf.AppendString("CODE_LINE=")
+		buf.AppendInt(int64(rec.Caller.Line))
+		buf.AppendByte('\n')
+		if rec.Caller.Function != "" {
+			appendJournalString(buf, "CODE_FUNC", rec.Caller.Function)
+		}
+	}
+
+	var pathBuf [4]string
+	path := pathBuf[:0]
+	for i, nf := 0, rec.NumFields(); i < nf; i++ {
+		f := rec.FieldAt(i)
+		if f.Type == FieldNamespace {
+			path = append(path, f.Key)
+			continue
+		}
+		appendJournalField(buf, val, path, f)
+	}
+	if rec.Stack != "" {
+		appendJournalString(buf, "STACKTRACE", rec.Stack)
+	}
+
+	_, err := h.conn.Write(buf.Bytes())
+	if err != nil && journalTooLarge(err) {
+		err = journalSendFD(h.conn, buf.Bytes())
+	}
+	return err
+}
+
+// Close closes the socket.
+func (h *JournalHandler) Close() error {
+	return h.conn.Close()
+}
+
+// appendJournalField writes f as NAME=value, flattening groups.
+func appendJournalField(buf, val *Buffer, path []string, f *Field) {
+	if f.Type == FieldLazy {
+		r := f.resolveLazy()
+		f = &r
+	}
+	if f.Type == FieldGroup {
+		fields, _ := f.Iface.([]Field)
+		var pathBuf [8]string
+		sub := append(append(pathBuf[:0], path...), f.Key)
+		for i := range fields {
+			if fields[i].Type == FieldNamespace {
+				sub = append(sub, fields[i].Key)
+				continue
+			}
+			appendJournalField(buf, val, sub, &fields[i])
+		}
+		return
+	}
+
+	// Render the name into val to check it against the reserved names,
+	// then reuse val for the value.
+	val.Reset()
+	appendJournalName(val, path, f.Key)
+	if val.Len() == 0 {
+		return
+	}
+	if _, ok := journalReserved[string(val.B)]; ok {
+		buf.AppendString("FIELD_")
+	}
+	buf.AppendBytes(val.B)
+	val.Reset()
+	appendFieldPlain(val, f)
+	appendJournalValue(buf, val.Bytes())
+}
+
+// journalReserved holds the field names the handler sets itself.
+var journalReserved = map[string]struct{}{
+	"MESSAGE":           {},
+	"PRIORITY":          {},
+	"SYSLOG_IDENTIFIER": {},
+	"LOGGER":            {},
+	"CODE_FILE":         {},
+	"CODE_LINE":         {},
+	"CODE_FUNC":         {},
+	"STACKTRACE":        {},
+}
+
+// journalCodeFile returns the caller's full source path. CallerInfo.File
+// is shortened for display, so the path is looked up from the PC when
+// there is one.
+func journalCodeFile(c CallerInfo) string {
+	if c.PC != 0 {
+		if frame, _ := runtime.CallersFrames([]uintptr{c.PC}).Next(); frame.File != "" {
+			return frame.File
+		}
+	}
+	return c.File
+}
+
+func appendJournalString(buf *Buffer, name, value string) {
+	buf.AppendString(name)
+	if strings.IndexByte(value, '\n') < 0 {
+		buf.AppendByte('=')
+		buf.AppendString(value)
+		buf.AppendByte('\n')
+		return
+	}
+	buf.AppendByte('\n')
+	buf.B = binary.LittleEndian.AppendUint64(buf.B, uint64(len(value)))
+	buf.AppendString(value)
+	buf.AppendByte('\n')
+}
+
+// appendJournalValue writes =value, or the binary-safe form
+// \n<uint64 LE length><value> when value contains a newline.
+func appendJournalValue(buf *Buffer, value []byte) {
+	for _, c := range value {
+		if c == '\n' {
+			buf.AppendByte('\n')
+			buf.B = binary.LittleEndian.AppendUint64(buf.B, uint64(len(value)))
+			buf.AppendBytes(value)
+			buf.AppendByte('\n')
+			return
+		}
+	}
+	buf.AppendByte('=')
+	buf.AppendBytes(value)
+	buf.AppendByte('\n')
+}
+
+// appendJournalName writes a journald field name: uppercase ASCII letters,
+// digits, and '_', not starting with '_' or a digit, at most 64 bytes.
+// Path segments are joined with '_'. Nothing is written for a key with no
+// usable characters.
+func appendJournalName(buf *Buffer, path []string, key string) {
+	n := 0
+	write := func(s string) {
+		for i := 0; i < len(s) && n < 64; i++ {
+			c := s[i]
+			switch {
+			case c >= 'a' && c <= 'z':
+				c -= 'a' - 'A'
+			case c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+			default:
+				c = '_'
+			}
+			if n == 0 && (c == '_' || c >= '0' && c <= '9') {
+				continue
+			}
+			buf.AppendByte(c)
+			n++
+		}
+	}
+	for _, p := range path {
+		write(p)
+		if n > 0 {
+			write("_")
+		}
+	}
+	write(key)
+}
@@ -16,8 +16,11 @@ func NewJSONHandler(w WriteSyncer, opts ...JSONOption) *JSONHandler {
 	for _, opt := range opts {
 		opt(cfg)
 	}
+	if cfg.lvar == nil {
+		cfg.lvar = NewLevelVar(cfg.level)
+	}
 	return &JSONHandler{
-		BaseHandler: NewBaseHandler(cfg.enc, cfg.writer, cfg.level),
+		BaseHandler: NewBaseHandlerVar(cfg.enc, cfg.writer, cfg.lvar),
 	}
 }
 
@@ -25,6 +28,7 @@ type jsonConfig struct {
 	enc    *JSONEncoder
 	writer WriteSyncer
 	level  Level
+	lvar   *LevelVar
 }
 
 // JSONOption configures a JSONHandler.
@@ -35,6 +39,12 @@ func WithJSONLevel(l Level) JSONOption {
 	return func(c *jsonConfig) { c.level = l }
 }
 
+// WithJSONLevelVar reads the minimum level from a shared LevelVar,
+// overriding WithJSONLevel.
+func WithJSONLevelVar(v *LevelVar) JSONOption {
+	return func(c *jsonConfig) { c.lvar = v }
+}
+
 // WithJSONTimeLayout sets the time format.
 func WithJSONTimeLayout(layout string) JSONOption {
 	return func(c *jsonConfig) { c.enc.TimeLayout = layout }
@@ -54,3 +64,13 @@ func WithJSONKeys(timeKey, levelKey, msgKey string) JSONOption {
 		}
 	}
 }
+
+// WithJSONNameKey sets the key used for the logger name. Default: "logger".
+func WithJSONNameKey(key string) JSONOption {
+	return func(c *jsonConfig) { c.enc.NameKey = key }
+}
+
+// WithJSONHexBytes encodes Bytes fields as hex instead of base64.
+func WithJSONHexBytes() JSONOption {
+	return func(c *jsonConfig) { c.enc.HexBytes = true }
+}
@@ -16,8 +16,11 @@ func NewLogfmtHandler(w WriteSyncer, opts ...LogfmtOption) *LogfmtHandler {
 	for _, opt := range opts {
 		opt(cfg)
 	}
+	if cfg.lvar == nil {
+		cfg.lvar = NewLevelVar(cfg.level)
+	}
 	return &LogfmtHandler{
-		BaseHandler: NewBaseHandler(cfg.enc, cfg.writer, cfg.level),
+		BaseHandler: NewBaseHandlerVar(cfg.enc, cfg.writer, cfg.lvar),
 	}
 }
 
@@ -25,6 +28,7 @@ type logfmtConfig struct {
 	enc    *LogfmtEncoder
 	writer WriteSyncer
 	level  Level
+	lvar   *LevelVar
 }
 
 // LogfmtOption configures a LogfmtHandler.
@@ -34,3 +38,14 @@ type LogfmtOption func(*logfmtConfig)
 func WithLogfmtLevel(l Level) LogfmtOption {
 	return func(c *logfmtConfig) { c.level = l }
 }
+
+// WithLogfmtLevelVar reads the minimum level from a shared LevelVar,
+// overriding WithLogfmtLevel.
+func WithLogfmtLevelVar(v *LevelVar) LogfmtOption {
+	return func(c *logfmtConfig) { c.lvar = v }
+}
+
+// WithLogfmtTimeLayout sets the time format.
+func WithLogfmtTimeLayout(layout string) LogfmtOption {
+	return func(c *logfmtConfig) { c.enc.TimeLayout = layout }
+}
@@ -0,0 +1,458 @@
+package loghq
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"math"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// RedactMode selects how a matched value is rewritten.
+type RedactMode uint8
+
+const (
+	// RedactFull replaces the whole value with the mask string.
+	RedactFull RedactMode = iota
+	// RedactPartial keeps the last four characters and masks the rest.
+	RedactPartial
+	// RedactHash replaces the value with a salted SHA-256 digest, so equal
+	// values can still be correlated without being revealed.
+	RedactHash
+)
+
+const defaultRedactMask = "[REDACTED]"
+
+// redactRule matches fields either as a whole (match) or by the substrings
+// of their string value (re).
+type redactRule struct {
+	mode  RedactMode
+	match func(f *Field) bool
+	re    *regexp.Regexp
+}
+
+// RedactingHandler rewrites sensitive field values before passing records to
+// the wrapped handler. Because it operates on the Record, it covers fields
+// bound with With/WithFields, fields from ContextWithFields, and key-value
+// pairs alike, including fields nested in groups and the members of
+// ObjectMarshaler and ArrayMarshaler values. The caller's record is never
+// modified: matching records are copied before rewriting.
+type RedactingHandler struct {
+	handler Handler
+	rules   []redactRule
+	salt    []byte
+	mask    string
+}
+
+// RedactOption configures a RedactingHandler.
+type RedactOption func(*RedactingHandler)
+
+// RedactKeys redacts fields whose key is one of keys.
+func RedactKeys(mode RedactMode, keys ...string) RedactOption {
+	set := make(map[string]struct{}, len(keys))
+	for _, k := range keys {
+		set[k] = struct{}{}
+	}
+	return RedactFunc(mode, func(f *Field) bool {
+		_, ok := set[f.Key]
+		return ok
+	})
+}
+
+// RedactKeyGlob redacts fields whose key matches a path.Match pattern,
+// such as "*_token" or "auth.*".
+func RedactKeyGlob(mode RedactMode, pattern string) RedactOption {
+	return RedactFunc(mode, func(f *Field) bool {
+		ok, _ := path.Match(pattern, f.Key)
+		return ok
+	})
+}
+
+// RedactPattern redacts every match of re inside string and error values,
+// leaving the rest of the value intact.
+func RedactPattern(mode RedactMode, re *regexp.Regexp) RedactOption {
+	return func(h *RedactingHandler) {
+		h.rules = append(h.rules, redactRule{mode: mode, re: re})
+	}
+}
+
+// RedactFunc redacts fields for which fn returns true.
+func RedactFunc(mode RedactMode, fn func(f *Field) bool) RedactOption {
+	return func(h *RedactingHandler) {
+		h.rules = append(h.rules, redactRule{mode: mode, match: fn})
+	}
+}
+
+// WithRedactSalt sets the salt prepended to values hashed by RedactHash.
+func WithRedactSalt(salt []byte) RedactOption {
+	return func(h *RedactingHandler) { h.salt = salt }
+}
+
+// WithRedactMask sets the replacement used by RedactFull. Default: "[REDACTED]".
+func WithRedactMask(mask string) RedactOption {
+	return func(h *RedactingHandler) { h.mask = mask }
+}
+
+// NewRedactingHandler wraps h with the given redaction rules. Rules are
+// applied in order; the first rule that matches a field wins.
+func NewRedactingHandler(h Handler, opts ...RedactOption) *RedactingHandler {
+	r := &RedactingHandler{handler: h, mask: defaultRedactMask}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+func (r *RedactingHandler) Enabled(lvl Level) bool {
+	return r.handler.Enabled(lvl)
+}
+
+// Handle redacts matching fields on a copy of rec and forwards it.
+// Records with nothing to redact are passed through untouched.
+func (r *RedactingHandler) Handle(rec *Record) error {
+	first := -1
+	for i, n := 0, rec.NumFields(); i < n; i++ {
+		if r.matches(rec.FieldAt(i)) {
+			first = i
+			break
+		}
+	}
+	if first < 0 {
+		return r.handler.Handle(rec)
+	}
+
+	cp := acquireRecord()
+	rec.copyTo(cp)
+	for i, n := first, cp.NumFields(); i < n; i++ {
+		r.redactField(cp.FieldAt(i))
+	}
+	err := r.handler.Handle(cp)
+	releaseRecord(cp)
+	return err
+}
+
+// Flush flushes the wrapped handler if it implements Flusher.
+func (r *RedactingHandler) Flush() error {
+	return flushHandler(r.handler)
+}
+
+// Close closes the wrapped handler if it implements Closer.
+func (r *RedactingHandler) Close() error {
+	return closeHandler(r.handler)
+}
+
+// matches reports whether a rule matches f itself or, for groups, objects
+// and arrays, any of its members. A rule matching a group's own key covers
+// the whole group. Namespaces are never matched themselves: they carry no
+// value, and the fields after them are checked one by one.
+func (r *RedactingHandler) matches(f *Field) bool {
+	for i := range r.rules {
+		rule := &r.rules[i]
+		if rule.match != nil && f.Type != FieldNamespace && rule.match(f) {
+			return true
+		}
+		if rule.re != nil && (f.Type == FieldString || f.Type == FieldError) && rule.re.MatchString(f.Str) {
+			return true
+		}
+	}
+	switch f.Type {
+	case FieldError:
+		if obj, ok := f.asErrorObject(); ok {
+			return r.matches(&obj)
+		}
+	case FieldGroup:
+		fields, _ := f.Iface.([]Field)
+		return r.anyMatches(fields)
+	case FieldObject:
+		if m, ok := f.Iface.(ObjectMarshaler); ok {
+			return r.objectMatches(m)
+		}
+	case FieldArray:
+		if m, ok := f.Iface.(ArrayMarshaler); ok {
+			return r.arrayMatches(m)
+		}
+	}
+	return false
+}
+
+// objectMatches and arrayMatches walk a marshaler's members through a
+// pooled collector that checks them against the rules instead of keeping
+// them, so a record with nothing to redact is not copied member by member.
+func (r *RedactingHandler) objectMatches(m ObjectMarshaler) bool {
+	c := acquireMatcher(r)
+	if err := m.MarshalLogObject(c); err != nil {
+		c.EncodeError("error", err.Error())
+	}
+	return releaseMatcher(c)
+}
+
+func (r *RedactingHandler) arrayMatches(m ArrayMarshaler) bool {
+	c := acquireMatcher(r)
+	if err := m.MarshalLogArray(c); err != nil {
+		c.AppendString(err.Error())
+	}
+	return releaseMatcher(c)
+}
+
+func (r *RedactingHandler) anyMatches(fields []Field) bool {
+	for i := range fields {
+		if r.matches(&fields[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactField rewrites f in place according to the first matching rule.
+// Group members are redacted in a fresh slice so bound fields stay intact.
+// Objects and arrays with a matching member are captured member by member,
+// and the redacted capture replaces the original marshaler. Structured errors are
+// redacted as the object they encode to, so the rules also reach their
+// LogFields and the messages of their causes.
+func (r *RedactingHandler) redactField(f *Field) {
+	for i := range r.rules {
+		rule := &r.rules[i]
+		if rule.match != nil && f.Type != FieldNamespace && rule.match(f) {
+			*f = Field{Key: f.Key, Type: FieldString, Str: r.redact(rule.mode, fieldValueString(f))}
+			return
+		}
+		if rule.re != nil && (f.Type == FieldString || f.Type == FieldError) && rule.re.MatchString(f.Str) {
+			if obj, ok := f.asErrorObject(); ok {
+				*f = obj
+				break
+			}
+			f.Str = rule.re.ReplaceAllStringFunc(f.Str, func(s string) string {
+				return r.redact(rule.mode, s)
+			})
+			// Handlers that take the error itself, such as SlogHandler,
+			// would see the unredacted text.
+			if f.Type == FieldError {
+				f.Iface = nil
+			}
+			return
+		}
+	}
+	switch f.Type {
+	case FieldError:
+		if obj, ok := f.asErrorObject(); ok {
+			if m := obj.Iface.(errorObject); r.objectMatches(m) {
+				*f = Field{Key: f.Key, Type: FieldObject, Iface: fieldsObject(r.redactFields(collectObject(m)))}
+			}
+		}
+	case FieldGroup:
+		fields, _ := f.Iface.([]Field)
+		if r.anyMatches(fields) {
+			f.Iface = r.redactFields(fields)
+		}
+	case FieldObject:
+		if m, ok := f.Iface.(ObjectMarshaler); ok && r.objectMatches(m) {
+			f.Iface = fieldsObject(r.redactFields(collectObject(m)))
+		}
+	case FieldArray:
+		if m, ok := f.Iface.(ArrayMarshaler); ok && r.arrayMatches(m) {
+			f.Iface = fieldsArray(r.redactFields(collectArray(m)))
+		}
+	}
+}
+
+func (r *RedactingHandler) redactFields(fields []Field) []Field {
+	cp := make([]Field, len(fields))
+	copy(cp, fields)
+	for i := range cp {
+		r.redactField(&cp[i])
+	}
+	return cp
+}
+
+func (r *RedactingHandler) redact(mode RedactMode, s string) string {
+	switch mode {
+	case RedactPartial:
+		n := utf8.RuneCountInString(s)
+		if n <= 4 {
+			return strings.Repeat("*", n)
+		}
+		i := len(s)
+		for k := 0; k < 4; k++ {
+			_, size := utf8.DecodeLastRuneInString(s[:i])
+			i -= size
+		}
+		return strings.Repeat("*", n-4) + s[i:]
+	case RedactHash:
+		h := sha256.New()
+		h.Write(r.salt)
+		h.Write([]byte(s))
+		return "sha256:" + hex.EncodeToString(h.Sum(nil))
+	default:
+		return r.mask
+	}
+}
+
+// fieldValueString renders a field's value as plain text.
+func fieldValueString(f *Field) string {
+	switch f.Type {
+	case FieldString, FieldError:
+		return f.Str
+	case FieldInt64:
+		return strconv.FormatInt(f.Ival, 10)
+	case FieldUint64:
+		return strconv.FormatUint(uint64(f.Ival), 10)
+	case FieldBytes:
+		b, _ := f.Iface.([]byte)
+		return string(b)
+	case FieldStringer:
+		return stringerValue(f.Iface)
+	case FieldFloat64:
+		return strconv.FormatFloat(math.Float64frombits(uint64(f.Ival)), 'f', -1, 64)
+	case FieldBool:
+		return strconv.FormatBool(f.Ival == 1)
+	case FieldDuration:
+		return time.Duration(f.Ival).String()
+	case FieldTime:
+		if t, ok := f.Iface.(time.Time); ok {
+			return t.Format(time.RFC3339Nano)
+		}
+		return ""
+	default:
+		return formatAny(f.Iface)
+	}
+}
+
+// fieldCollector captures what an ObjectMarshaler encodes as Fields, so
+// redaction rules can see its members. A collector with a handler set
+// keeps nothing and only records whether a member matched its rules.
+type fieldCollector struct {
+	fields []Field
+
+	r       *RedactingHandler
+	f       Field
+	matched bool
+}
+
+func collectObject(m ObjectMarshaler) []Field {
+	c := &fieldCollector{}
+	if err := m.MarshalLogObject(c); err != nil {
+		c.EncodeError("error", err.Error())
+	}
+	return c.fields
+}
+
+func (c *fieldCollector) add(f Field) {
+	if c.r == nil {
+		c.fields = append(c.fields, f)
+		return
+	}
+	if !c.matched {
+		c.f = f
+		c.matched = c.r.matches(&c.f)
+	}
+}
+
+func (c *fieldCollector) EncodeString(key, val string)                 { c.add(String(key, val)) }
+func (c *fieldCollector) EncodeInt64(key string, val int64)            { c.add(Int64(key, val)) }
+func (c *fieldCollector) EncodeUint64(key string, val uint64)          { c.add(Uint64(key, val)) }
+func (c *fieldCollector) EncodeFloat64(key string, val float64)        { c.add(Float64(key, val)) }
+func (c *fieldCollector) EncodeBool(key string, val bool)              { c.add(Bool(key, val)) }
+func (c *fieldCollector) EncodeDuration(key string, val time.Duration) { c.add(Duration(key, val)) }
+func (c *fieldCollector) EncodeTime(key string, val time.Time)         { c.add(Time(key, val)) }
+func (c *fieldCollector) EncodeBytes(key string, val []byte)           { c.add(Bytes(key, val)) }
+func (c *fieldCollector) EncodeComplex128(key string, val complex128)  { c.add(Complex128(key, val)) }
+func (c *fieldCollector) EncodeError(key string, msg string) {
+	c.add(Field{Key: key, Type: FieldError, Str: msg})
+}
+func (c *fieldCollector) EncodeAny(key string, val interface{})        { c.add(Any(key, val)) }
+func (c *fieldCollector) EncodeGroup(key string, fields []Field)       { c.add(Group(key, fields...)) }
+func (c *fieldCollector) OpenNamespace(key string)                     { c.add(Namespace(key)) }
+func (c *fieldCollector) EncodeObject(key string, obj ObjectMarshaler) { c.add(Object(key, obj)) }
+func (c *fieldCollector) EncodeArray(key string, arr ArrayMarshaler)   { c.add(Array(key, arr)) }
+
+// elementCollector captures the elements of an ArrayMarshaler as keyless
+// Fields.
+type elementCollector struct{ fieldCollector }
+
+func collectArray(m ArrayMarshaler) []Field {
+	c := &elementCollector{}
+	if err := m.MarshalLogArray(c); err != nil {
+		c.AppendString(err.Error())
+	}
+	return c.fields
+}
+
+var matcherPool = sync.Pool{New: func() interface{} { return &elementCollector{} }}
+
+func acquireMatcher(r *RedactingHandler) *elementCollector {
+	c := matcherPool.Get().(*elementCollector)
+	c.r, c.matched = r, false
+	return c
+}
+
+// releaseMatcher returns c to the pool and reports whether a member matched.
+func releaseMatcher(c *elementCollector) bool {
+	matched := c.matched
+	c.r, c.f = nil, Field{}
+	matcherPool.Put(c)
+	return matched
+}
+
+func (c *elementCollector) AppendString(val string)          { c.EncodeString("", val) }
+func (c *elementCollector) AppendInt64(val int64)            { c.EncodeInt64("", val) }
+func (c *elementCollector) AppendUint64(val uint64)          { c.EncodeUint64("", val) }
+func (c *elementCollector) AppendFloat64(val float64)        { c.EncodeFloat64("", val) }
+func (c *elementCollector) AppendBool(val bool)              { c.EncodeBool("", val) }
+func (c *elementCollector) AppendDuration(val time.Duration) { c.EncodeDuration("", val) }
+func (c *elementCollector) AppendTime(val time.Time)         { c.EncodeTime("", val) }
+func (c *elementCollector) AppendAny(val interface{})        { c.EncodeAny("", val) }
+func (c *elementCollector) AppendObject(obj ObjectMarshaler) { c.EncodeObject("", obj) }
+func (c *elementCollector) AppendArray(arr ArrayMarshaler)   { c.EncodeArray("", arr) }
+
+// fieldsObject and fieldsArray encode a redacted capture in place of the
+// original marshaler.
+type (
+	fieldsObject []Field
+	fieldsArray  []Field
+)
+
+func (o fieldsObject) MarshalLogObject(enc FieldEncoder) error {
+	for i := range o {
+		o[i].Encode(enc)
+	}
+	return nil
+}
+
+func (a fieldsArray) MarshalLogArray(enc ArrayEncoder) error {
+	for i := range a {
+		f := &a[i]
+		switch f.Type {
+		case FieldInt64:
+			enc.AppendInt64(f.Ival)
+		case FieldUint64:
+			enc.AppendUint64(uint64(f.Ival))
+		case FieldFloat64:
+			enc.AppendFloat64(math.Float64frombits(uint64(f.Ival)))
+		case FieldBool:
+			enc.AppendBool(f.Ival == 1)
+		case FieldDuration:
+			enc.AppendDuration(time.Duration(f.Ival))
+		case FieldTime:
+			t, _ := f.Iface.(time.Time)
+			enc.AppendTime(t)
+		case FieldAny:
+			enc.AppendAny(f.Iface)
+		case FieldObject:
+			if m, ok := f.Iface.(ObjectMarshaler); ok {
+				enc.AppendObject(m)
+			}
+		case FieldArray:
+			if m, ok := f.Iface.(ArrayMarshaler); ok {
+				enc.AppendArray(m)
+			}
+		default:
+			enc.AppendString(fieldValueString(f))
+		}
+	}
+	return nil
+}
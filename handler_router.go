@@ -0,0 +1,245 @@
+package loghq
+
+import (
+	"math"
+	"reflect"
+	"strings"
+)
+
+// RouteMatcher reports whether a record belongs to a route.
+type RouteMatcher func(rec *Record) bool
+
+// MatchLevels matches records with min <= level <= max.
+func MatchLevels(min, max Level) RouteMatcher {
+	return func(rec *Record) bool { return rec.Level >= min && rec.Level <= max }
+}
+
+// MatchMinLevel matches records at or above min.
+func MatchMinLevel(min Level) RouteMatcher {
+	return func(rec *Record) bool { return rec.Level >= min }
+}
+
+// MatchMessagePrefix matches records whose message starts with prefix.
+func MatchMessagePrefix(prefix string) RouteMatcher {
+	return func(rec *Record) bool { return strings.HasPrefix(rec.Message, prefix) }
+}
+
+// MatchField matches records that carry a top-level field named key.
+func MatchField(key string) RouteMatcher {
+	return func(rec *Record) bool {
+		_, ok := rec.Lookup(key)
+		return ok
+	}
+}
+
+// MatchFieldValue matches records whose top-level field key equals val.
+// Values are compared by type where possible (MatchFieldValue("audit",
+// true) matches Bool("audit", true)) and by their text otherwise
+// (MatchFieldValue("status", "500") matches Int("status", 500)).
+func MatchFieldValue(key string, val interface{}) RouteMatcher {
+	want := toField(key, val)
+	wantText := fieldValueString(&want)
+	return func(rec *Record) bool {
+		f, ok := rec.Lookup(key)
+		if !ok {
+			return false
+		}
+		if eq, comparable := fieldValueEqual(f, &want); comparable {
+			return eq
+		}
+		return fieldValueString(f) == wantText
+	}
+}
+
+// MatchCallerPackage matches records logged from one of pkgs or their
+// subpackages, by import path. Records without caller info never match.
+func MatchCallerPackage(pkgs ...string) RouteMatcher {
+	return func(rec *Record) bool {
+		p := rec.Caller.Package
+		for _, pkg := range pkgs {
+			if p == pkg || (len(p) > len(pkg) && p[len(pkg)] == '/' && p[:len(pkg)] == pkg) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// MatchAny matches records accepted by any of ms.
+func MatchAny(ms ...RouteMatcher) RouteMatcher {
+	return func(rec *Record) bool {
+		for _, m := range ms {
+			if m(rec) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// MatchNot inverts m.
+func MatchNot(m RouteMatcher) RouteMatcher {
+	return func(rec *Record) bool { return !m(rec) }
+}
+
+// fieldValueEqual compares two fields of the same scalar type without
+// formatting them. comparable is false when the types differ or aren't
+// scalars, in which case callers fall back to comparing text.
+func fieldValueEqual(f, want *Field) (eq, comparable bool) {
+	if f.Type != want.Type {
+		return false, false
+	}
+	switch f.Type {
+	case FieldString, FieldError:
+		return f.Str == want.Str, true
+	case FieldInt64, FieldUint64, FieldBool, FieldDuration:
+		return f.Ival == want.Ival, true
+	case FieldFloat64:
+		return math.Float64frombits(uint64(f.Ival)) == math.Float64frombits(uint64(want.Ival)), true
+	}
+	return false, false
+}
+
+type route struct {
+	handler  Handler
+	matchers []RouteMatcher
+}
+
+func (r *route) match(rec *Record) bool {
+	for _, m := range r.matchers {
+		if !m(rec) {
+			return false
+		}
+	}
+	return true
+}
+
+// RouterHandler sends each record to the handlers whose routes match it.
+// A route matches when all of its matchers do; a route with no matchers
+// matches everything. By default the first matching route wins; with
+// WithRouteAll every matching route receives the record. Records that match
+// no route go to the fallback handler, if any.
+//
+//	router := loghq.NewRouterHandler(
+//		loghq.WithRoute(auditFile, loghq.MatchFieldValue("audit", true)),
+//		loghq.WithRoute(loghq.NewMultiHandler(errorFile, stderr), loghq.MatchMinLevel(loghq.ErrorLevel)),
+//		loghq.WithRouteFallback(stdoutJSON),
+//	)
+type RouterHandler struct {
+	routes   []route
+	fallback Handler
+	all      bool
+}
+
+// RouterOption configures a RouterHandler.
+type RouterOption func(*RouterHandler)
+
+// WithRoute adds a route. Routes are tried in the order they are added.
+func WithRoute(h Handler, matchers ...RouteMatcher) RouterOption {
+	return func(r *RouterHandler) {
+		r.routes = append(r.routes, route{handler: h, matchers: matchers})
+	}
+}
+
+// WithRouteFallback sets the handler for records that match no route.
+func WithRouteFallback(h Handler) RouterOption {
+	return func(r *RouterHandler) { r.fallback = h }
+}
+
+// WithRouteAll delivers records to every matching route instead of only
+// the first.
+func WithRouteAll() RouterOption {
+	return func(r *RouterHandler) { r.all = true }
+}
+
+// NewRouterHandler creates a routing handler.
+func NewRouterHandler(opts ...RouterOption) *RouterHandler {
+	r := &RouterHandler{}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Enabled reports whether any route's handler, or the fallback, accepts
+// lvl. Matchers are not consulted, since they need the full record.
+func (r *RouterHandler) Enabled(lvl Level) bool {
+	for i := range r.routes {
+		if r.routes[i].handler.Enabled(lvl) {
+			return true
+		}
+	}
+	return r.fallback != nil && r.fallback.Enabled(lvl)
+}
+
+// Handle routes the record, returning the first handler error.
+func (r *RouterHandler) Handle(rec *Record) error {
+	var firstErr error
+	matched := false
+	for i := range r.routes {
+		rt := &r.routes[i]
+		if !rt.match(rec) {
+			continue
+		}
+		matched = true
+		if rt.handler.Enabled(rec.Level) {
+			if err := rt.handler.Handle(rec); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+		if !r.all {
+			return firstErr
+		}
+	}
+	if !matched && r.fallback != nil && r.fallback.Enabled(rec.Level) {
+		return r.fallback.Handle(rec)
+	}
+	return firstErr
+}
+
+// Flush flushes every route handler and the fallback.
+func (r *RouterHandler) Flush() error {
+	var firstErr error
+	for _, h := range r.handlers() {
+		if err := flushHandler(h); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// Close closes every route handler and the fallback. A handler used by
+// several routes is closed once.
+func (r *RouterHandler) Close() error {
+	var firstErr error
+	for _, h := range r.handlers() {
+		if err := closeHandler(h); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// handlers returns the distinct handlers in route order. Handlers whose
+// dynamic type is not comparable, such as structs holding a slice, would
+// panic in ==, so they are never treated as duplicates.
+func (r *RouterHandler) handlers() []Handler {
+	hs := make([]Handler, 0, len(r.routes)+1)
+	add := func(h Handler) {
+		if reflect.TypeOf(h).Comparable() {
+			for _, seen := range hs {
+				if seen == h {
+					return
+				}
+			}
+		}
+		hs = append(hs, h)
+	}
+	for i := range r.routes {
+		add(r.routes[i].handler)
+	}
+	if r.fallback != nil {
+		add(r.fallback)
+	}
+	return hs
+}
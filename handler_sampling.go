@@ -0,0 +1,213 @@
+package loghq
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	samplerLevels  = len(levelNames)
+	samplerBuckets = 4096
+)
+
+// samplerCounter tracks how often one (level, message) bucket was seen in
+// the current tick. All fields are updated atomically.
+type samplerCounter struct {
+	resetAt    atomic.Int64
+	counter    atomic.Uint64
+	suppressed atomic.Uint64
+	msg        atomic.Pointer[string] // first suppressed message, for summaries
+}
+
+// incCheckReset increments the counter, starting a new tick if the current
+// one has expired. It returns the new count and, when a tick rolled over,
+// the number of records suppressed during the previous tick.
+func (c *samplerCounter) incCheckReset(now int64, tick time.Duration) (n, suppressed uint64) {
+	resetAt := c.resetAt.Load()
+	if resetAt > now {
+		return c.counter.Add(1), 0
+	}
+	c.counter.Store(1)
+	if !c.resetAt.CompareAndSwap(resetAt, now+int64(tick)) {
+		// Another goroutine reset the counter first; count against its tick.
+		return c.counter.Add(1), 0
+	}
+	return 1, c.suppressed.Swap(0)
+}
+
+// SamplingHandler rate-limits repetitive records. Within each tick, the
+// first N records with a given level and message pass through, then every
+// Mth after that; the rest are dropped.
+//
+// Counters are kept in a fixed-size hash table updated with atomics, so
+// sampling costs no locks and no allocations. Distinct messages may
+// occasionally share a bucket and be sampled together.
+type SamplingHandler struct {
+	handler    Handler
+	tick       time.Duration
+	first      uint64
+	thereafter uint64
+	summary    bool
+	dropped    atomic.Uint64
+	counts     [samplerLevels][samplerBuckets]samplerCounter
+
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// SamplingOption configures a SamplingHandler.
+type SamplingOption func(*SamplingHandler)
+
+// WithSamplingSummary emits a summary record for each message that had
+// records suppressed in a tick. The summary repeats the level and message
+// and carries a "suppressed" count field. It is written when the message
+// recurs after the tick rolls over, or at the latest by a background sweep
+// one tick later, so drops of messages that stop recurring are reported
+// too. Close reports what is still pending and stops the sweep. Each
+// message costs one small allocation per tick in which it is suppressed.
+func WithSamplingSummary() SamplingOption {
+	return func(s *SamplingHandler) { s.summary = true }
+}
+
+// NewSamplingHandler wraps h, allowing first records per tick for each
+// (level, message) pair and every thereafter-th record after that.
+// A thereafter of 0 drops everything past the first records.
+func NewSamplingHandler(h Handler, tick time.Duration, first, thereafter int, opts ...SamplingOption) *SamplingHandler {
+	s := &SamplingHandler{
+		handler:    h,
+		tick:       tick,
+		first:      uint64(first),
+		thereafter: uint64(thereafter),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.summary && s.tick > 0 {
+		s.stop = make(chan struct{})
+		s.done = make(chan struct{})
+		go s.sweepLoop()
+	}
+	return s
+}
+
+func (s *SamplingHandler) Enabled(lvl Level) bool {
+	return s.handler.Enabled(lvl)
+}
+
+// Handle forwards rec if it is within the sampling budget.
+func (s *SamplingHandler) Handle(rec *Record) error {
+	t := rec.Time
+	if t.IsZero() {
+		t = time.Now()
+	}
+
+	c := &s.counts[samplerLevelIndex(rec.Level)][fnv32a(rec.Message)%samplerBuckets]
+	n, suppressed := c.incCheckReset(t.UnixNano(), s.tick)
+
+	if suppressed > 0 && s.summary {
+		s.writeSummary(t, rec.Level, rec.Message, suppressed)
+	}
+
+	if n > s.first && (s.thereafter == 0 || (n-s.first)%s.thereafter != 0) {
+		if c.suppressed.Add(1) == 1 && s.summary {
+			msg := rec.Message
+			c.msg.Store(&msg)
+		}
+		s.dropped.Add(1)
+		return nil
+	}
+	return s.handler.Handle(rec)
+}
+
+func (s *SamplingHandler) writeSummary(t time.Time, lvl Level, msg string, suppressed uint64) {
+	sum := acquireRecord()
+	sum.Time = t
+	sum.Level = lvl
+	sum.Message = msg
+	sum.AddField(Field{Key: "suppressed", Type: FieldInt64, Ival: int64(suppressed)})
+	_ = s.handler.Handle(sum)
+	releaseRecord(sum)
+}
+
+// sweepLoop writes the summaries of expired ticks every tick until Close.
+func (s *SamplingHandler) sweepLoop() {
+	defer close(s.done)
+	ticker := time.NewTicker(s.tick)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case now := <-ticker.C:
+			s.sweep(now, false)
+		}
+	}
+}
+
+// sweep writes a summary for every bucket with suppressed records whose
+// tick ended before now, or for every such bucket if all is set.
+func (s *SamplingHandler) sweep(now time.Time, all bool) {
+	for i := range s.counts {
+		for j := range s.counts[i] {
+			c := &s.counts[i][j]
+			if c.suppressed.Load() == 0 || (!all && c.resetAt.Load() > now.UnixNano()) {
+				continue
+			}
+			if n := c.suppressed.Swap(0); n > 0 {
+				if msg := c.msg.Load(); msg != nil {
+					s.writeSummary(now, Level(i-2), *msg, n)
+				}
+			}
+		}
+	}
+}
+
+// Dropped returns the total number of records suppressed by sampling.
+func (s *SamplingHandler) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// Flush flushes the wrapped handler if it implements Flusher.
+func (s *SamplingHandler) Flush() error {
+	return flushHandler(s.handler)
+}
+
+// Close stops the summary sweep, writing any summaries still pending, then
+// closes the wrapped handler if it implements Closer.
+func (s *SamplingHandler) Close() error {
+	if s.stop != nil {
+		s.closeOnce.Do(func() {
+			close(s.stop)
+			<-s.done
+			s.sweep(time.Now(), true)
+		})
+	}
+	return closeHandler(s.handler)
+}
+
+func samplerLevelIndex(lvl Level) int {
+	idx := int(lvl) + 2
+	if idx < 0 {
+		return 0
+	}
+	if idx >= samplerLevels {
+		return samplerLevels - 1
+	}
+	return idx
+}
+
+// fnv32a hashes s without converting it to a []byte.
+func fnv32a(s string) uint32 {
+	const (
+		offset32 = 2166136261
+		prime32  = 16777619
+	)
+	h := uint32(offset32)
+	for i := 0; i < len(s); i++ {
+		h ^= uint32(s[i])
+		h *= prime32
+	}
+	return h
+}
@@ -0,0 +1,114 @@
+package loghq
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"time"
+)
+
+// SlogHandler forwards records to an arbitrary slog.Handler.
+// Levels are mapped with SlogLevel so SuccessLevel and TraceLevel survive
+// the trip; the stack trace, if any, is attached as a "stack" attr. The
+// caller's program counter and the record's context are passed on, so
+// HandlerOptions.AddSource reports where the record was logged.
+type SlogHandler struct {
+	handler slog.Handler
+}
+
+// NewSlogHandler creates a handler that writes through the given slog.Handler.
+func NewSlogHandler(h slog.Handler) *SlogHandler {
+	return &SlogHandler{handler: h}
+}
+
+func (s *SlogHandler) Enabled(lvl Level) bool {
+	return s.handler.Enabled(context.Background(), SlogLevel(lvl))
+}
+
+// Handle converts the record to a slog.Record and passes it on.
+func (s *SlogHandler) Handle(rec *Record) error {
+	r := slog.NewRecord(rec.Time, SlogLevel(rec.Level), rec.Message, rec.Caller.PC)
+	if rec.Name != "" {
+		r.AddAttrs(slog.String("logger", rec.Name))
+	}
+	fields := make([]Field, 0, rec.NumFields())
+	rec.EachField(func(f *Field) {
+		fields = append(fields, *f)
+	})
+	r.AddAttrs(slogAttrsFromFields(fields)...)
+	if rec.Stack != "" {
+		r.AddAttrs(slog.String("stack", rec.Stack))
+	}
+	ctx := rec.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return s.handler.Handle(ctx, r)
+}
+
+// slogAttrsFromFields converts fields to attrs. A namespace turns the
+// remaining fields into a slog group named after it.
+func slogAttrsFromFields(fields []Field) []slog.Attr {
+	attrs := make([]slog.Attr, 0, len(fields))
+	for i := range fields {
+		if fields[i].Type == FieldNamespace {
+			return append(attrs, slog.Attr{
+				Key:   fields[i].Key,
+				Value: slog.GroupValue(slogAttrsFromFields(fields[i+1:])...),
+			})
+		}
+		attrs = append(attrs, slogAttrFromField(&fields[i]))
+	}
+	return attrs
+}
+
+// slogAttrFromField maps a typed Field to the closest slog.Attr.
+func slogAttrFromField(f *Field) slog.Attr {
+	switch f.Type {
+	case FieldString:
+		return slog.String(f.Key, f.Str)
+	case FieldInt64:
+		return slog.Int64(f.Key, f.Ival)
+	case FieldUint64:
+		return slog.Uint64(f.Key, uint64(f.Ival))
+	case FieldStringer:
+		return slog.String(f.Key, stringerValue(f.Iface))
+	case FieldFloat64:
+		return slog.Float64(f.Key, math.Float64frombits(uint64(f.Ival)))
+	case FieldBool:
+		return slog.Bool(f.Key, f.Ival == 1)
+	case FieldDuration:
+		return slog.Duration(f.Key, time.Duration(f.Ival))
+	case FieldTime:
+		if t, ok := f.Iface.(time.Time); ok {
+			return slog.Time(f.Key, t)
+		}
+	case FieldError:
+		// Pass the error itself so slog handlers can inspect it.
+		if err, ok := f.Iface.(error); ok {
+			return slog.Any(f.Key, err)
+		}
+		return slog.String(f.Key, f.Str)
+	case FieldGroup:
+		fields, _ := f.Iface.([]Field)
+		return slog.Attr{Key: f.Key, Value: slog.GroupValue(slogAttrsFromFields(fields)...)}
+	case FieldObject:
+		// slog handlers know nothing of ObjectMarshaler; capture the
+		// members as a group instead.
+		if m, ok := f.Iface.(ObjectMarshaler); ok {
+			return slog.Attr{Key: f.Key, Value: slog.GroupValue(slogAttrsFromFields(collectObject(m))...)}
+		}
+	case FieldArray, FieldComplex:
+		// Arrays and complex numbers have no slog kind, and JSONHandler
+		// rejects complex128, so they travel as their text form.
+		buf := getBuffer()
+		appendFieldPlain(buf, f)
+		s := string(buf.B)
+		putBuffer(buf)
+		return slog.String(f.Key, s)
+	case FieldLazy:
+		r := f.resolveLazy()
+		return slogAttrFromField(&r)
+	}
+	return slog.Any(f.Key, f.Iface)
+}
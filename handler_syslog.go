@@ -0,0 +1,90 @@
+package loghq
+
+// SyslogHandler writes syslog messages through any WriteSyncer, such as a
+// NetWriter connected to rsyslog or syslog-ng.
+// Thin configuration wrapper over BaseHandler.
+type SyslogHandler struct {
+	*BaseHandler
+}
+
+// NewSyslogHandler creates a handler that writes RFC 5424 messages with
+// newline framing, facility user, and a header filled in from the host
+// name, program name, and process ID.
+//
+//	nw, _ := loghq.NewNetWriter(loghq.NetConfig{Network: "tcp", Address: "localhost:514"})
+//	h := loghq.NewSyslogHandler(nw, loghq.WithSyslogFraming(loghq.SyslogFramingOctetCounting))
+func NewSyslogHandler(w WriteSyncer, opts ...SyslogOption) *SyslogHandler {
+	cfg := &syslogConfig{
+		writer: w,
+		level:  TraceLevel,
+		enc:    NewSyslogEncoder(),
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if cfg.lvar == nil {
+		cfg.lvar = NewLevelVar(cfg.level)
+	}
+	return &SyslogHandler{
+		BaseHandler: NewBaseHandlerVar(cfg.enc, cfg.writer, cfg.lvar),
+	}
+}
+
+type syslogConfig struct {
+	enc    *SyslogEncoder
+	writer WriteSyncer
+	level  Level
+	lvar   *LevelVar
+}
+
+// SyslogOption configures a SyslogHandler.
+type SyslogOption func(*syslogConfig)
+
+// WithSyslogLevel sets the minimum level.
+func WithSyslogLevel(l Level) SyslogOption {
+	return func(c *syslogConfig) { c.level = l }
+}
+
+// WithSyslogLevelVar reads the minimum level from a shared LevelVar,
+// overriding WithSyslogLevel.
+func WithSyslogLevelVar(v *LevelVar) SyslogOption {
+	return func(c *syslogConfig) { c.lvar = v }
+}
+
+// WithSyslogRFC3164 switches to the legacy BSD syslog format.
+func WithSyslogRFC3164() SyslogOption {
+	return func(c *syslogConfig) { c.enc.Format = SyslogRFC3164 }
+}
+
+// WithSyslogFraming sets the message framing. Use
+// SyslogFramingOctetCounting for TCP and SyslogFramingNone for UDP.
+func WithSyslogFraming(f SyslogFraming) SyslogOption {
+	return func(c *syslogConfig) { c.enc.Framing = f }
+}
+
+// WithSyslogFacility sets the facility. Default: FacilityUser.
+func WithSyslogFacility(f SyslogFacility) SyslogOption {
+	return func(c *syslogConfig) { c.enc.Facility = f }
+}
+
+// WithSyslogHeader overrides the host name, application name, and process
+// ID sent in each message. Empty arguments keep the defaults.
+func WithSyslogHeader(hostname, appName, procID string) SyslogOption {
+	return func(c *syslogConfig) {
+		if hostname != "" {
+			c.enc.Hostname = hostname
+		}
+		if appName != "" {
+			c.enc.AppName = appName
+		}
+		if procID != "" {
+			c.enc.ProcID = procID
+		}
+	}
+}
+
+// WithSyslogSDID sets the structured data ID for fields. Default:
+// "fields@32473".
+func WithSyslogSDID(id string) SyslogOption {
+	return func(c *syslogConfig) { c.enc.SDID = id }
+}
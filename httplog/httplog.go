@@ -0,0 +1,258 @@
+// Package httplog provides net/http middleware that logs one structured
+// access record per request through loghq.
+//
+//	logger := loghq.New(loghq.WithHandler(loghq.NewJSONHandler(os.Stdout)))
+//	http.ListenAndServe(":8080", httplog.Middleware(logger)(mux))
+//
+// Each request gets an ID, taken from the X-Request-ID header when the
+// client sends a valid one and generated otherwise, which is echoed in the
+// response. Handlers reach the request-scoped logger, already carrying
+// request_id, through the request context:
+//
+//	func handle(w http.ResponseWriter, r *http.Request) {
+//		loghq.InfoContext(r.Context(), "loading cart")
+//	}
+package httplog
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/Bhavyyadav25/loghq"
+	"github.com/Bhavyyadav25/loghq/internal/requestid"
+)
+
+// DefaultRequestIDHeader is the header used to receive and return request
+// IDs.
+const DefaultRequestIDHeader = "X-Request-ID"
+
+// Option configures the middleware.
+type Option func(*config)
+
+type config struct {
+	header      string
+	generate    func() string
+	trustHeader bool
+	skip        func(*http.Request) bool
+	statusLevel func(status int) loghq.Level
+}
+
+// WithRequestIDHeader sets the request ID header. Default:
+// DefaultRequestIDHeader.
+func WithRequestIDHeader(name string) Option {
+	return func(c *config) { c.header = name }
+}
+
+// WithRequestIDGenerator sets the function that creates request IDs.
+// Default: 26 random base32 characters.
+func WithRequestIDGenerator(fn func() string) Option {
+	return func(c *config) { c.generate = fn }
+}
+
+// WithTrustRequestID controls whether an incoming request ID header is
+// propagated. Disable it on edge servers facing untrusted clients.
+// Default: true.
+func WithTrustRequestID(on bool) Option {
+	return func(c *config) { c.trustHeader = on }
+}
+
+// WithSkip suppresses the access record for requests where fn returns
+// true, such as health checks. The request still gets an ID, a logger, and
+// panic recovery.
+func WithSkip(fn func(*http.Request) bool) Option {
+	return func(c *config) { c.skip = fn }
+}
+
+// WithStatusLevel sets how a response status maps to the access record's
+// level. Default: ErrorLevel for 5xx, WarnLevel for 4xx, InfoLevel
+// otherwise.
+func WithStatusLevel(fn func(status int) loghq.Level) Option {
+	return func(c *config) { c.statusLevel = fn }
+}
+
+// StatusLevel is the default status to level mapping.
+func StatusLevel(status int) loghq.Level {
+	switch {
+	case status >= 500:
+		return loghq.ErrorLevel
+	case status >= 400:
+		return loghq.WarnLevel
+	default:
+		return loghq.InfoLevel
+	}
+}
+
+type ctxRequestIDKey struct{}
+
+// RequestID returns the ID assigned to the request by the middleware, or
+// "" outside it.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(ctxRequestIDKey{}).(string)
+	return id
+}
+
+// Middleware returns middleware that logs through logger. For each
+// request it:
+//
+//   - assigns a request ID and sets it on the response header;
+//   - stores logger.With(request_id) in the context (see loghq.FromContext);
+//   - recovers panics, logging them at ErrorLevel with the stack and
+//     answering 500 if nothing was written yet;
+//   - logs an access record with method, path, status, bytes, duration,
+//     remote_addr, and user_agent.
+func Middleware(logger *loghq.Logger, opts ...Option) func(http.Handler) http.Handler {
+	cfg := &config{
+		header:      DefaultRequestIDHeader,
+		generate:    rand.Text,
+		trustHeader: true,
+		statusLevel: StatusLevel,
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	// Access records always come from this file and are not errors in the
+	// program, so caller and stack would only add noise. Panics always
+	// carry the stack, whatever the logger's stack level. Both share the
+	// logger's LevelVar, so later level changes reach them.
+	level := loghq.WithLevelVar(logger.LevelVar())
+	access := logger.WithOptions(level, loghq.WithCaller(false), loghq.WithStackLevel(loghq.FatalLevel+1))
+	panics := logger.WithOptions(level, loghq.WithStackLevel(loghq.ErrorLevel))
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+
+			id := ""
+			if cfg.trustHeader {
+				id = r.Header.Get(cfg.header)
+			}
+			if !requestid.Valid(id) {
+				id = cfg.generate()
+			}
+			w.Header().Set(cfg.header, id)
+
+			reqID := loghq.String("request_id", id)
+			ctx := context.WithValue(r.Context(), ctxRequestIDKey{}, id)
+			ctx = loghq.IntoContext(ctx, logger.With(reqID))
+			r = r.WithContext(ctx)
+
+			rw := &responseWriter{ResponseWriter: w}
+			defer func() {
+				if rv := recover(); rv != nil {
+					if rv == http.ErrAbortHandler {
+						// net/http's sentinel for aborting a response; let
+						// the server handle it silently.
+						panic(rv)
+					}
+					panics.With(reqID).Error("panic recovered",
+						"panic", rv,
+						"method", r.Method,
+						"path", r.URL.Path,
+					)
+					if !rw.wroteHeader {
+						http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+					} else {
+						rw.status = http.StatusInternalServerError
+					}
+				}
+
+				if cfg.skip != nil && cfg.skip(r) {
+					return
+				}
+				status := rw.Status()
+				lvl := cfg.statusLevel(status)
+				if !access.Enabled(lvl) {
+					return
+				}
+				fields := []loghq.Field{
+					reqID,
+					loghq.String("method", r.Method),
+					loghq.String("path", r.URL.Path),
+					loghq.Int("status", status),
+					loghq.Int64("bytes", rw.bytes),
+					loghq.Duration("duration", time.Since(start)),
+					loghq.String("remote_addr", r.RemoteAddr),
+					loghq.String("user_agent", r.UserAgent()),
+				}
+				// Never Panic or Fatal: an access record must not stop the
+				// server.
+				access.With(fields...).Log(r.Context(), min(lvl, loghq.ErrorLevel), "request")
+			}()
+
+			next.ServeHTTP(rw, r)
+		})
+	}
+}
+
+// responseWriter records the status and body size written by a handler.
+type responseWriter struct {
+	http.ResponseWriter
+	status      int
+	bytes       int64
+	wroteHeader bool
+}
+
+func (w *responseWriter) WriteHeader(code int) {
+	if !w.wroteHeader {
+		// 1xx responses are informational; the final status comes later.
+		if code >= 100 && code <= 199 && code != http.StatusSwitchingProtocols {
+			w.ResponseWriter.WriteHeader(code)
+			return
+		}
+		w.status = code
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *responseWriter) Write(p []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	n, err := w.ResponseWriter.Write(p)
+	w.bytes += int64(n)
+	return n, err
+}
+
+// Status returns the response status, or 200 if the handler wrote
+// nothing.
+func (w *responseWriter) Status() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
+
+// Flush implements http.Flusher when the underlying writer does.
+func (w *responseWriter) Flush() {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack implements http.Hijacker when the underlying writer does.
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("httplog: %T does not support hijacking", w.ResponseWriter)
+	}
+	if !w.wroteHeader {
+		w.status = http.StatusSwitchingProtocols
+		w.wroteHeader = true
+	}
+	return h.Hijack()
+}
+
+// Unwrap returns the underlying writer for http.ResponseController.
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
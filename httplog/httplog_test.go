@@ -0,0 +1,261 @@
+package httplog
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Bhavyyadav25/loghq"
+)
+
+type testWriter struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (w *testWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.Write(p)
+}
+func (w *testWriter) Sync() error { return nil }
+
+// records decodes the JSON lines written so far.
+func (w *testWriter) records(t *testing.T) []map[string]interface{} {
+	t.Helper()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var out []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(w.buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var m map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &m); err != nil {
+			t.Fatalf("bad JSON %q: %v", line, err)
+		}
+		out = append(out, m)
+	}
+	return out
+}
+
+func newLogger(w *testWriter) *loghq.Logger {
+	return loghq.New(loghq.WithHandler(loghq.NewJSONHandler(w)), loghq.WithLevel(loghq.TraceLevel))
+}
+
+func TestAccessRecord(t *testing.T) {
+	w := &testWriter{}
+	h := Middleware(newLogger(w))(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		loghq.InfoContext(r.Context(), "inside handler")
+		rw.WriteHeader(http.StatusCreated)
+		rw.Write([]byte("hello"))
+	}))
+
+	req := httptest.NewRequest("POST", "/orders?x=1", nil)
+	req.Header.Set("User-Agent", "test-agent")
+	resp := httptest.NewRecorder()
+	h.ServeHTTP(resp, req)
+
+	id := resp.Header().Get(DefaultRequestIDHeader)
+	if len(id) != 26 {
+		t.Fatalf("generated request ID = %q", id)
+	}
+	recs := w.records(t)
+	if len(recs) != 2 {
+		t.Fatalf("got %d records: %v", len(recs), recs)
+	}
+	if recs[0]["msg"] != "inside handler" || recs[0]["request_id"] != id {
+		t.Errorf("handler record: %v", recs[0])
+	}
+	access := recs[1]
+	want := map[string]interface{}{
+		"msg":         "request",
+		"level":       "INFO",
+		"request_id":  id,
+		"method":      "POST",
+		"path":        "/orders",
+		"status":      float64(201),
+		"bytes":       float64(5),
+		"remote_addr": "192.0.2.1:1234",
+		"user_agent":  "test-agent",
+	}
+	for k, v := range want {
+		if access[k] != v {
+			t.Errorf("access %s = %v, want %v", k, access[k], v)
+		}
+	}
+	if _, ok := access["duration"]; !ok {
+		t.Error("access record has no duration")
+	}
+	if _, ok := access["caller"]; ok {
+		t.Error("access record has a caller")
+	}
+}
+
+func TestRequestIDPropagation(t *testing.T) {
+	var seen string
+	next := http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		seen = RequestID(r.Context())
+	})
+
+	for _, tt := range []struct {
+		name     string
+		incoming string
+		opts     []Option
+		want     string
+	}{
+		{"propagated", "abc-123", nil, "abc-123"},
+		{"invalid", "bad id\n", nil, "generated"},
+		{"too long", strings.Repeat("a", 129), nil, "generated"},
+		{"untrusted", "abc-123", []Option{WithTrustRequestID(false)}, "generated"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := append([]Option{WithRequestIDGenerator(func() string { return "generated" })}, tt.opts...)
+			h := Middleware(newLogger(&testWriter{}), opts...)(next)
+			req := httptest.NewRequest("GET", "/", nil)
+			req.Header.Set(DefaultRequestIDHeader, tt.incoming)
+			resp := httptest.NewRecorder()
+			h.ServeHTTP(resp, req)
+			if seen != tt.want || resp.Header().Get(DefaultRequestIDHeader) != tt.want {
+				t.Errorf("request ID = %q, header %q, want %q", seen, resp.Header().Get(DefaultRequestIDHeader), tt.want)
+			}
+		})
+	}
+
+	h := Middleware(newLogger(&testWriter{}), WithRequestIDHeader("X-Trace"))(next)
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("X-Trace", "t-1")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+	if seen != "t-1" {
+		t.Errorf("custom header request ID = %q", seen)
+	}
+}
+
+func TestPanicRecovery(t *testing.T) {
+	w := &testWriter{}
+	h := Middleware(newLogger(w))(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		explode()
+	}))
+
+	resp := httptest.NewRecorder()
+	h.ServeHTTP(resp, httptest.NewRequest("GET", "/boom", nil))
+	if resp.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d", resp.Code)
+	}
+
+	recs := w.records(t)
+	if len(recs) != 2 {
+		t.Fatalf("got %d records: %v", len(recs), recs)
+	}
+	p := recs[0]
+	if p["level"] != "ERROR" || p["panic"] != "kaboom" || p["path"] != "/boom" || p["request_id"] == nil {
+		t.Errorf("panic record: %v", p)
+	}
+	stack, _ := p["stack"].(string)
+	if !strings.Contains(stack, "httplog.explode") {
+		t.Errorf("stack does not include the panic site:\n%s", stack)
+	}
+	if recs[1]["status"] != float64(500) || recs[1]["level"] != "ERROR" {
+		t.Errorf("access record: %v", recs[1])
+	}
+	if _, ok := recs[1]["stack"]; ok {
+		t.Error("access record has a stack")
+	}
+}
+
+func explode() {
+	panic("kaboom")
+}
+
+func TestAbortHandlerPanics(t *testing.T) {
+	h := Middleware(newLogger(&testWriter{}))(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+	defer func() {
+		if rv := recover(); rv != http.ErrAbortHandler {
+			t.Errorf("recovered %v, want http.ErrAbortHandler", rv)
+		}
+	}()
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+}
+
+func TestSkipAndStatusLevel(t *testing.T) {
+	w := &testWriter{}
+	h := Middleware(newLogger(w),
+		WithSkip(func(r *http.Request) bool { return r.URL.Path == "/healthz" }),
+		WithStatusLevel(func(status int) loghq.Level {
+			if status == http.StatusNotFound {
+				return loghq.DebugLevel
+			}
+			return StatusLevel(status)
+		}),
+	)(http.NotFoundHandler())
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/healthz", nil))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/missing", nil))
+
+	recs := w.records(t)
+	if len(recs) != 1 || recs[0]["path"] != "/missing" || recs[0]["level"] != "DEBUG" {
+		t.Errorf("records: %v", recs)
+	}
+}
+
+func TestLevelChangeAfterMiddleware(t *testing.T) {
+	w := &testWriter{}
+	logger := newLogger(w)
+	h := Middleware(logger)(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/panic" {
+			panic("boom")
+		}
+	}))
+
+	logger.SetLevel(loghq.ErrorLevel)
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/ok", nil))
+	if recs := w.records(t); len(recs) != 0 {
+		t.Errorf("access record ignored the new level: %v", recs)
+	}
+
+	logger.SetLevel(loghq.InfoLevel)
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/ok", nil))
+	logger.SetLevel(loghq.FatalLevel)
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/panic", nil))
+	if recs := w.records(t); len(recs) != 1 || recs[0]["path"] != "/ok" {
+		t.Errorf("records: %v", recs)
+	}
+}
+
+func TestStatusLevel(t *testing.T) {
+	for status, want := range map[int]loghq.Level{
+		200: loghq.InfoLevel,
+		302: loghq.InfoLevel,
+		404: loghq.WarnLevel,
+		503: loghq.ErrorLevel,
+	} {
+		if got := StatusLevel(status); got != want {
+			t.Errorf("StatusLevel(%d) = %v, want %v", status, got, want)
+		}
+	}
+}
+
+func TestResponseWriterInterfaces(t *testing.T) {
+	srv := httptest.NewServer(Middleware(newLogger(&testWriter{}))(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Write([]byte("a"))
+		if err := http.NewResponseController(rw).Flush(); err != nil {
+			t.Errorf("Flush: %v", err)
+		}
+		if _, ok := rw.(http.Hijacker); !ok {
+			t.Error("writer does not implement http.Hijacker")
+		}
+	})))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+}
@@ -0,0 +1,18 @@
+// Package requestid validates request IDs received from clients. It is
+// shared by httplog and grpclog so both accept the same IDs.
+package requestid
+
+// Valid reports whether id is 1 to 128 bytes of printable ASCII. Incoming
+// IDs that fail it are replaced, so a client cannot inject control
+// characters or oversized values into logs.
+func Valid(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < '!' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
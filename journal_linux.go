@@ -0,0 +1,65 @@
+//go:build linux
+
+package loghq
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+const journalShmPrefix = "/dev/shm"
+
+// journalTooLarge reports whether a datagram send failed because of its
+// size.
+func journalTooLarge(err error) bool {
+	return errors.Is(err, syscall.EMSGSIZE) || errors.Is(err, syscall.ENOBUFS)
+}
+
+// journalSendFD passes data to journald as a file descriptor: a sealed
+// memfd when the kernel supports it, otherwise an unlinked file in
+// /dev/shm, matching sd_journal_sendv.
+func journalSendFD(conn *net.UnixConn, data []byte) error {
+	f, err := journalMemfd()
+	if err != nil {
+		if f, err = os.CreateTemp(journalShmPrefix, "loghq-journal-"); err != nil {
+			return fmt.Errorf("loghq: cannot create journal payload file: %w", err)
+		}
+		os.Remove(f.Name())
+	}
+	defer f.Close()
+
+	if _, err := f.Write(data); err != nil {
+		return err
+	}
+	// Sealing fails harmlessly for the /dev/shm fallback.
+	unix.FcntlInt(f.Fd(), unix.F_ADD_SEALS, unix.F_SEAL_SEAL|unix.F_SEAL_SHRINK|unix.F_SEAL_GROW|unix.F_SEAL_WRITE)
+
+	// WriteMsgUnix refuses connected datagram sockets, so send directly.
+	raw, err := conn.SyscallConn()
+	if err != nil {
+		return err
+	}
+	rights := syscall.UnixRights(int(f.Fd()))
+	var sendErr error
+	err = raw.Write(func(fd uintptr) bool {
+		sendErr = syscall.Sendmsg(int(fd), nil, rights, nil, 0)
+		return sendErr != syscall.EAGAIN
+	})
+	if err != nil {
+		return err
+	}
+	return sendErr
+}
+
+func journalMemfd() (*os.File, error) {
+	fd, err := unix.MemfdCreate("loghq-journal", unix.MFD_CLOEXEC|unix.MFD_ALLOW_SEALING)
+	if err != nil {
+		return nil, err
+	}
+	return os.NewFile(uintptr(fd), "loghq-journal"), nil
+}
@@ -0,0 +1,72 @@
+//go:build linux
+
+package loghq
+
+import (
+	"io"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestJournalHandlerLargeRecord(t *testing.T) {
+	srv, path := listenJournal(t)
+	h, err := NewJournalHandler(WithJournalSocket(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h.Close()
+
+	big := strings.Repeat("x", 4<<20)
+	if err := h.Handle(&Record{Level: InfoLevel, Message: "big", Time: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	rec := acquireRecord()
+	rec.Level = ErrorLevel
+	rec.Message = "big"
+	rec.AddField(String("payload", big))
+	err = h.Handle(rec)
+	releaseRecord(rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv.SetReadDeadline(time.Now().Add(5 * time.Second))
+	buf := make([]byte, 1024)
+	if _, err := srv.Read(buf); err != nil { // the small record
+		t.Fatal(err)
+	}
+
+	oob := make([]byte, syscall.CmsgSpace(4))
+	_, oobn, _, _, err := srv.ReadMsgUnix(buf, oob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fd := receivedFD(t, oob[:oobn])
+	f := os.NewFile(uintptr(fd), "payload")
+	defer f.Close()
+	f.Seek(0, io.SeekStart) // journald reads from offset 0; the offset is shared with the sender
+	data, err := io.ReadAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := parseJournal(t, data)
+	if got["PAYLOAD"] != big || got["PRIORITY"] != "3" {
+		t.Errorf("unexpected payload: %d bytes, priority %q", len(got["PAYLOAD"]), got["PRIORITY"])
+	}
+}
+
+func receivedFD(t *testing.T, oob []byte) int {
+	t.Helper()
+	msgs, err := syscall.ParseSocketControlMessage(oob)
+	if err != nil || len(msgs) != 1 {
+		t.Fatalf("expected one control message: %v", err)
+	}
+	fds, err := syscall.ParseUnixRights(&msgs[0])
+	if err != nil || len(fds) != 1 {
+		t.Fatalf("expected one fd: %v", err)
+	}
+	return fds[0]
+}
@@ -0,0 +1,17 @@
+//go:build !linux
+
+package loghq
+
+import (
+	"errors"
+	"net"
+)
+
+// journald only exists on Linux; elsewhere oversized records are reported
+// as errors instead of being passed by file descriptor.
+
+func journalTooLarge(error) bool { return false }
+
+func journalSendFD(*net.UnixConn, []byte) error {
+	return errors.New("loghq: journal file descriptor passing requires linux")
+}
@@ -1,6 +1,10 @@
 package loghq
 
-import "strconv"
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
 
 // Level defines log severity levels.
 type Level int8
@@ -13,9 +17,14 @@ const (
 	WarnLevel    Level = 2
 	ErrorLevel   Level = 3
 	FatalLevel   Level = 4
+
+	// PanicLevel was added after FatalLevel and numbered above it so that
+	// FatalLevel kept its value. A threshold of FatalLevel therefore still
+	// lets Panic records through.
+	PanicLevel Level = 5
 )
 
-var levelNames = [7]string{
+var levelNames = [8]string{
 	"TRACE",
 	"DEBUG",
 	"INFO",
@@ -23,6 +32,20 @@ var levelNames = [7]string{
 	"WARN",
 	"ERROR",
 	"FATAL",
+	"PANIC",
+}
+
+// lowerLevelNames holds the names logfmt writes, so encoding a level does
+// not allocate.
+var lowerLevelNames = [8]string{
+	"trace",
+	"debug",
+	"info",
+	"ok",
+	"warn",
+	"error",
+	"fatal",
+	"panic",
 }
 
 // String returns the human-readable level name.
@@ -34,6 +57,15 @@ func (l Level) String() string {
 	return "LEVEL(" + strconv.Itoa(int(l)) + ")"
 }
 
+// lowerString returns the lower-case level name.
+func (l Level) lowerString() string {
+	idx := int(l) + 2
+	if idx >= 0 && idx < len(lowerLevelNames) {
+		return lowerLevelNames[idx]
+	}
+	return strings.ToLower(l.String())
+}
+
 // Enabled returns true if this level is at or above the given threshold.
 func (l Level) Enabled(threshold Level) bool {
 	return l >= threshold
@@ -54,9 +86,43 @@ func ParseLevel(s string) Level {
 		return WarnLevel
 	case "error", "ERROR":
 		return ErrorLevel
+	case "panic", "PANIC":
+		return PanicLevel
 	case "fatal", "FATAL":
 		return FatalLevel
 	default:
 		return InfoLevel
 	}
 }
+
+// MarshalText implements encoding.TextMarshaler using the lowercase level
+// name, so levels round-trip through JSON and text configuration.
+func (l Level) MarshalText() ([]byte, error) {
+	return []byte(l.lowerString()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. Unlike ParseLevel it
+// rejects unknown names instead of falling back to InfoLevel.
+func (l *Level) UnmarshalText(text []byte) error {
+	switch strings.ToLower(string(text)) {
+	case "trace":
+		*l = TraceLevel
+	case "debug":
+		*l = DebugLevel
+	case "info":
+		*l = InfoLevel
+	case "success", "ok":
+		*l = SuccessLevel
+	case "warn", "warning":
+		*l = WarnLevel
+	case "error":
+		*l = ErrorLevel
+	case "panic":
+		*l = PanicLevel
+	case "fatal":
+		*l = FatalLevel
+	default:
+		return fmt.Errorf("loghq: unknown level %q", text)
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package loghq
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// LevelRules holds per-component level overrides for named loggers. A
+// logger named "payments.ledger" uses the rule with the longest matching
+// prefix — "payments.ledger", then "payments" — and falls back to its own
+// level when none match. Prefixes match whole dot-separated segments, so
+// "pay" does not match "payments".
+//
+// Rules are shared by every logger derived from the same root and can be
+// changed at any time; loggers pick up changes on their next log call.
+type LevelRules struct {
+	mu  sync.Mutex
+	set atomic.Pointer[levelRuleSet]
+}
+
+// levelRuleSet is an immutable snapshot, sorted longest prefix first.
+type levelRuleSet struct {
+	rules []levelRule
+}
+
+type levelRule struct {
+	prefix string
+	level  Level
+}
+
+// levelCache memoizes a named logger's lookup against one snapshot.
+type levelCache struct {
+	set   *levelRuleSet
+	level Level
+	ok    bool
+}
+
+// NewLevelRules returns an empty rule set.
+func NewLevelRules() *LevelRules {
+	return &LevelRules{}
+}
+
+// ParseLevelRules parses a comma-separated list of name=level pairs, as in
+// LOGHQ_LEVELS=payments=debug,cache=warn.
+func ParseLevelRules(spec string) (map[string]Level, error) {
+	rules := make(map[string]Level)
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, text, ok := strings.Cut(part, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("loghq: invalid level rule %q: expected name=level", part)
+		}
+		var lvl Level
+		if err := lvl.UnmarshalText([]byte(strings.TrimSpace(text))); err != nil {
+			return nil, err
+		}
+		rules[name] = lvl
+	}
+	return rules, nil
+}
+
+// Set adds or replaces the rule for prefix.
+func (r *LevelRules) Set(prefix string, lvl Level) {
+	r.update(func(m map[string]Level) { m[prefix] = lvl })
+}
+
+// Delete removes the rule for prefix.
+func (r *LevelRules) Delete(prefix string) {
+	r.update(func(m map[string]Level) { delete(m, prefix) })
+}
+
+// Replace discards all rules and installs rules.
+func (r *LevelRules) Replace(rules map[string]Level) {
+	r.update(func(m map[string]Level) {
+		clear(m)
+		for k, v := range rules {
+			m[k] = v
+		}
+	})
+}
+
+// Parse replaces all rules with those in spec (see ParseLevelRules).
+func (r *LevelRules) Parse(spec string) error {
+	rules, err := ParseLevelRules(spec)
+	if err != nil {
+		return err
+	}
+	r.Replace(rules)
+	return nil
+}
+
+// Rules returns a copy of the current rules.
+func (r *LevelRules) Rules() map[string]Level {
+	m := make(map[string]Level)
+	if set := r.set.Load(); set != nil {
+		for _, rule := range set.rules {
+			m[rule.prefix] = rule.level
+		}
+	}
+	return m
+}
+
+// Lookup returns the level of the longest rule matching name.
+func (r *LevelRules) Lookup(name string) (Level, bool) {
+	return r.load().lookup(name)
+}
+
+// String formats the rules in ParseLevelRules syntax, sorted by name.
+func (r *LevelRules) String() string {
+	set := r.load()
+	parts := make([]string, len(set.rules))
+	for i, rule := range set.rules {
+		parts[i] = rule.prefix + "=" + strings.ToLower(rule.level.String())
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ",")
+}
+
+func (r *LevelRules) update(fn func(map[string]Level)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	m := r.Rules()
+	fn(m)
+
+	set := &levelRuleSet{rules: make([]levelRule, 0, len(m))}
+	for prefix, lvl := range m {
+		set.rules = append(set.rules, levelRule{prefix: prefix, level: lvl})
+	}
+	sort.Slice(set.rules, func(i, j int) bool {
+		a, b := set.rules[i].prefix, set.rules[j].prefix
+		if len(a) != len(b) {
+			return len(a) > len(b)
+		}
+		return a < b
+	})
+	r.set.Store(set)
+}
+
+var emptyLevelRuleSet = &levelRuleSet{}
+
+func (r *LevelRules) load() *levelRuleSet {
+	if set := r.set.Load(); set != nil {
+		return set
+	}
+	return emptyLevelRuleSet
+}
+
+func (s *levelRuleSet) lookup(name string) (Level, bool) {
+	for _, rule := range s.rules {
+		if name == rule.prefix ||
+			(len(name) > len(rule.prefix) && name[len(rule.prefix)] == '.' && name[:len(rule.prefix)] == rule.prefix) {
+			return rule.level, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,243 @@
+package loghq
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// LevelVar is a Level that can be changed at runtime and shared between
+// loggers and handlers. Reads are a single atomic load; the zero value is
+// InfoLevel.
+//
+// LevelVar implements http.Handler so the level can be inspected and
+// changed operationally:
+//
+//	lv := loghq.NewLevelVar(loghq.InfoLevel)
+//	logger := loghq.New(loghq.WithLevelVar(lv), ...)
+//	http.Handle("/debug/loglevel", lv)
+//
+//	curl localhost:8080/debug/loglevel
+//	curl -X PUT -d debug 'localhost:8080/debug/loglevel?ttl=10m'
+//	curl -X PUT -H 'Content-Type: application/json' \
+//		-d '{"level":"debug","ttl":"10m"}' localhost:8080/debug/loglevel
+type LevelVar struct {
+	v atomic.Int32
+
+	mu       sync.Mutex
+	timer    *time.Timer
+	gen      uint64
+	revertTo Level
+	expires  time.Time
+}
+
+// NewLevelVar returns a LevelVar set to l.
+func NewLevelVar(l Level) *LevelVar {
+	v := &LevelVar{}
+	v.v.Store(int32(l))
+	return v
+}
+
+// Level returns the current level.
+func (v *LevelVar) Level() Level {
+	return Level(v.v.Load())
+}
+
+// Set changes the level and cancels any pending SetFor revert.
+func (v *LevelVar) Set(l Level) {
+	v.mu.Lock()
+	v.cancelRevertLocked()
+	v.v.Store(int32(l))
+	v.mu.Unlock()
+}
+
+// SetFor changes the level for ttl, then reverts to the level that was in
+// effect before the first of any overlapping SetFor calls. A later Set
+// cancels the revert. A ttl <= 0 behaves like Set.
+func (v *LevelVar) SetFor(l Level, ttl time.Duration) {
+	if ttl <= 0 {
+		v.Set(l)
+		return
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if v.timer == nil {
+		v.revertTo = v.Level()
+	} else {
+		v.timer.Stop()
+	}
+	v.gen++
+	gen := v.gen
+	v.expires = time.Now().Add(ttl)
+	v.v.Store(int32(l))
+	v.timer = time.AfterFunc(ttl, func() {
+		v.mu.Lock()
+		defer v.mu.Unlock()
+		if v.gen != gen {
+			return
+		}
+		v.v.Store(int32(v.revertTo))
+		v.timer = nil
+		v.expires = time.Time{}
+	})
+}
+
+// Expires reports when a pending SetFor revert fires, and whether one is
+// pending.
+func (v *LevelVar) Expires() (time.Time, bool) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.expires, v.timer != nil
+}
+
+func (v *LevelVar) cancelRevertLocked() {
+	if v.timer != nil {
+		v.timer.Stop()
+		v.timer = nil
+	}
+	v.gen++
+	v.expires = time.Time{}
+}
+
+// String returns the current level's name.
+func (v *LevelVar) String() string {
+	return v.Level().String()
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (v *LevelVar) MarshalText() ([]byte, error) {
+	return v.Level().MarshalText()
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (v *LevelVar) UnmarshalText(text []byte) error {
+	var l Level
+	if err := l.UnmarshalText(text); err != nil {
+		return err
+	}
+	v.Set(l)
+	return nil
+}
+
+// levelPayload is the JSON body accepted and returned by ServeHTTP.
+type levelPayload struct {
+	Level    *Level `json:"level,omitempty"`
+	TTL      string `json:"ttl,omitempty"`
+	Expires  string `json:"expires,omitempty"`
+	RevertTo *Level `json:"revert_to,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// ServeHTTP reports the level on GET and changes it on PUT.
+//
+// A PUT whose Content-Type is application/json carries {"level": "debug"};
+// any other body is the bare level name, and the response mirrors the
+// request's format. GET responds with JSON unless the Accept header asks
+// for text/plain. A PUT may carry a TTL either as the "ttl" JSON member or
+// as a ?ttl= query parameter (a Go duration such as "10m"); when it
+// expires the previous level is restored.
+func (v *LevelVar) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	plain := wantsPlainText(r)
+
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut:
+		lvl, ttl, err := v.decodeLevelRequest(r)
+		if err != nil {
+			writeLevelError(w, plain, http.StatusBadRequest, err)
+			return
+		}
+		v.SetFor(lvl, ttl)
+	default:
+		w.Header().Set("Allow", "GET, PUT")
+		writeLevelError(w, plain, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+		return
+	}
+
+	if plain {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		fmt.Fprintln(w, strings.ToLower(v.String()))
+		return
+	}
+
+	cur := v.Level()
+	resp := levelPayload{Level: &cur}
+	v.mu.Lock()
+	if v.timer != nil {
+		revert := v.revertTo
+		resp.Expires = v.expires.Format(time.RFC3339)
+		resp.RevertTo = &revert
+	}
+	v.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+func (v *LevelVar) decodeLevelRequest(r *http.Request) (Level, time.Duration, error) {
+	var (
+		lvl     Level
+		ttlText = r.URL.Query().Get("ttl")
+	)
+
+	if !isJSON(r.Header.Get("Content-Type")) {
+		body, err := io.ReadAll(io.LimitReader(r.Body, 1024))
+		if err != nil {
+			return 0, 0, err
+		}
+		if err := lvl.UnmarshalText([]byte(strings.TrimSpace(string(body)))); err != nil {
+			return 0, 0, err
+		}
+	} else {
+		var req levelPayload
+		if err := json.NewDecoder(io.LimitReader(r.Body, 1024)).Decode(&req); err != nil {
+			return 0, 0, fmt.Errorf("invalid request body: %w", err)
+		}
+		if req.Level == nil {
+			return 0, 0, fmt.Errorf("missing level")
+		}
+		lvl = *req.Level
+		if req.TTL != "" {
+			ttlText = req.TTL
+		}
+	}
+
+	var ttl time.Duration
+	if ttlText != "" {
+		var err error
+		if ttl, err = time.ParseDuration(ttlText); err != nil || ttl < 0 {
+			return 0, 0, fmt.Errorf("invalid ttl %q", ttlText)
+		}
+	}
+	return lvl, ttl, nil
+}
+
+func writeLevelError(w http.ResponseWriter, plain bool, status int, err error) {
+	if plain {
+		http.Error(w, err.Error(), status)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(levelPayload{Error: err.Error()})
+}
+
+// wantsPlainText reports whether the client is speaking plain text: a PUT
+// whose body isn't JSON, or a GET that accepts text/plain.
+func wantsPlainText(r *http.Request) bool {
+	if r.Method == http.MethodPut {
+		return !isJSON(r.Header.Get("Content-Type"))
+	}
+	return strings.Contains(r.Header.Get("Accept"), "text/plain")
+}
+
+func isJSON(contentType string) bool {
+	mt, _, _ := mime.ParseMediaType(contentType)
+	return mt == "application/json"
+}
@@ -10,15 +10,34 @@ import (
 
 // Logger is the core logging engine. It is safe for concurrent use.
 type Logger struct {
-	level      atomic.Int32
+	level      *LevelVar
+	shareLevel bool
+	rules      *LevelRules
+	name       string
+	ruleCache  *atomic.Pointer[levelCache]
 	handler    Handler
 	addCaller  bool
 	stackLevel Level
 	callerSkip int
 	fields     []Field
 	ctx        context.Context
+	extractors []ContextExtractor
+	clock      Clock
+	onFatal    func(*Logger)
+	exit       func(code int)
+	dev        bool
 }
 
+// Clock supplies record timestamps. See WithClock.
+type Clock interface {
+	Now() time.Time
+}
+
+// ClockFunc adapts a function to Clock.
+type ClockFunc func() time.Time
+
+func (f ClockFunc) Now() time.Time { return f() }
+
 // New creates a new Logger with the given options.
 // By default: InfoLevel, discard handler, caller capture enabled,
 // stack traces on ErrorLevel and above.
@@ -27,8 +46,9 @@ func New(opts ...Option) *Logger {
 		handler:    discardHandler{},
 		addCaller:  true,
 		stackLevel: ErrorLevel,
+		level:      NewLevelVar(InfoLevel),
+		rules:      NewLevelRules(),
 	}
-	l.level.Store(int32(InfoLevel))
 
 	for _, opt := range opts {
 		opt(l)
@@ -36,17 +56,30 @@ func New(opts ...Option) *Logger {
 	return l
 }
 
-// clone creates a shallow copy with independent fields slice.
+// clone creates a shallow copy with independent fields slice and level.
+// A LevelVar set with WithLevelVar stays shared.
 func (l *Logger) clone() *Logger {
 	c := &Logger{
+		level:      l.level,
+		shareLevel: l.shareLevel,
+		rules:      l.rules,
+		name:       l.name,
+		ruleCache:  l.ruleCache,
 		handler:    l.handler,
 		addCaller:  l.addCaller,
 		stackLevel: l.stackLevel,
 		callerSkip: l.callerSkip,
 		ctx:        l.ctx,
+		extractors: l.extractors,
+		clock:      l.clock,
+		onFatal:    l.onFatal,
+		exit:       l.exit,
+		dev:        l.dev,
 	}
-	c.level.Store(l.level.Load())
 
+	if !l.shareLevel {
+		c.level = NewLevelVar(l.level.Level())
+	}
 	if len(l.fields) > 0 {
 		c.fields = make([]Field, len(l.fields))
 		copy(c.fields, l.fields)
@@ -54,6 +87,18 @@ func (l *Logger) clone() *Logger {
 	return c
 }
 
+// WithOptions returns a new Logger with opts applied on top of this
+// logger's configuration. Fields, name, context, and level are kept.
+//
+//	quiet := logger.WithOptions(loghq.WithCaller(false), loghq.WithStackLevel(loghq.FatalLevel))
+func (l *Logger) WithOptions(opts ...Option) *Logger {
+	c := l.clone()
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
 // WithFields returns a new Logger with the given fields pre-bound.
 func (l *Logger) WithFields(f Fields) *Logger {
 	c := l.clone()
@@ -68,6 +113,44 @@ func (l *Logger) With(fields ...Field) *Logger {
 	return c
 }
 
+// WithGroup returns a new Logger that nests all subsequently added fields —
+// from With, context, and key-value pairs — under name.
+func (l *Logger) WithGroup(name string) *Logger {
+	if name == "" {
+		return l
+	}
+	return l.With(Namespace(name))
+}
+
+// Named returns a child logger whose name is the parent's name joined with
+// name by a dot ("payments" then "ledger" gives "payments.ledger"). The name
+// is rendered by every encoder and selects per-component level overrides
+// from LevelRules.
+func (l *Logger) Named(name string) *Logger {
+	if name == "" {
+		return l
+	}
+	c := l.clone()
+	if l.name != "" {
+		c.name = l.name + "." + name
+	} else {
+		c.name = name
+	}
+	c.ruleCache = new(atomic.Pointer[levelCache])
+	return c
+}
+
+// Name returns the logger's name, or "" for the root logger.
+func (l *Logger) Name() string {
+	return l.name
+}
+
+// LevelRules returns the per-component level overrides shared by this
+// logger and every logger derived from the same root.
+func (l *Logger) LevelRules() *LevelRules {
+	return l.rules
+}
+
 // WithContext returns a new Logger that extracts fields from the context.
 func (l *Logger) WithContext(ctx context.Context) *Logger {
 	c := l.clone()
@@ -75,29 +158,96 @@ func (l *Logger) WithContext(ctx context.Context) *Logger {
 	return c
 }
 
-// SetLevel changes the logger's level atomically.
+// SetLevel changes the logger's level atomically. Loggers derived with
+// With, WithFields, WithGroup, or WithContext start with a copy of the
+// level and are not affected, unless the level was set with WithLevelVar.
 func (l *Logger) SetLevel(lvl Level) {
-	l.level.Store(int32(lvl))
+	l.level.Set(lvl)
 }
 
-// log is the core hot path. Everything funnels through here.
-func (l *Logger) log(lvl Level, msg string, kvs []interface{}) {
-	// Lock-free level check — costs ~1ns when disabled.
-	if lvl < Level(l.level.Load()) {
-		return
+// LevelVar returns the logger's level variable, for exposing over HTTP or
+// sharing with other loggers through WithLevelVar.
+func (l *Logger) LevelVar() *LevelVar {
+	return l.level
+}
+
+// minLevel returns the threshold for this logger: the longest matching
+// LevelRules entry for its name, or its own level. Lookups are cached per
+// rules snapshot, so named loggers pay one pointer comparison per call.
+func (l *Logger) minLevel() Level {
+	if l.name != "" {
+		if set := l.rules.load(); len(set.rules) > 0 {
+			c := l.ruleCache.Load()
+			if c == nil || c.set != set {
+				lvl, ok := set.lookup(l.name)
+				c = &levelCache{set: set, level: lvl, ok: ok}
+				l.ruleCache.Store(c)
+			}
+			if c.ok {
+				return c.level
+			}
+		}
+	}
+	return l.level.Level()
+}
+
+// Enabled reports whether a record at lvl passes both the logger's level
+// and its handler's, so callers can skip expensive preparation. For single
+// values, Lazy fields do this automatically.
+func (l *Logger) Enabled(lvl Level) bool {
+	return lvl >= l.minLevel() && l.handler.Enabled(lvl)
+}
+
+// log is the core hot path. Everything funnels through here. ctx is the
+// context to read fields from: the one passed to an XxxContext method, or
+// the one bound with WithContext.
+func (l *Logger) log(ctx context.Context, lvl Level, msg string, kvs []interface{}) {
+	// Lock-free level check — costs ~1ns when disabled. The handler is
+	// asked too, so a record it would discard is never built and its lazy
+	// fields never run; a single handler with its own level relies on this.
+	if l.Enabled(lvl) {
+		l.write(ctx, lvl, msg, kvs)
+	}
+	// Panic and Fatal still panic and exit when their record is filtered out.
+	if lvl > ErrorLevel {
+		l.terminate(lvl, msg)
+	}
+}
+
+// dpanic logs at ErrorLevel and, in development mode, then panics. It sits
+// at the same depth as log so write reports the same caller.
+func (l *Logger) dpanic(ctx context.Context, msg string, kvs []interface{}) {
+	if l.Enabled(ErrorLevel) {
+		l.write(ctx, ErrorLevel, msg, kvs)
+	}
+	if l.dev {
+		l.terminate(PanicLevel, msg)
 	}
+}
 
+// write builds an enabled record and hands it to the handler. It is called
+// only from log and dpanic, which keeps the caller four frames up.
+func (l *Logger) write(ctx context.Context, lvl Level, msg string, kvs []interface{}) {
 	rec := acquireRecord()
-	rec.Time = time.Now()
+	if l.clock != nil {
+		rec.Time = l.clock.Now()
+	} else {
+		rec.Time = time.Now()
+	}
 	rec.Level = lvl
 	rec.Message = msg
+	rec.Name = l.name
+	rec.Context = ctx
 
 	// Pre-bound fields
 	rec.AddFields(l.fields)
 
 	// Context fields
-	if l.ctx != nil {
-		rec.AddFields(fieldsFromContext(l.ctx))
+	if ctx != nil {
+		rec.AddFields(fieldsFromContext(ctx))
+		for _, extract := range l.extractors {
+			rec.AddFields(extract(ctx))
+		}
 	}
 
 	// Parse slog-style key-value pairs directly into inline array.
@@ -117,48 +267,115 @@ func (l *Logger) log(lvl Level, msg string, kvs []interface{}) {
 		rec.AddField(toField(key, kvs[i+1]))
 	}
 
-	// Caller capture (skip 3 frames: log -> Trace/Info/etc -> user code)
+	// Caller capture (skip 4 frames: write -> log -> Trace/Info/etc -> user code)
 	if l.addCaller {
-		rec.Caller = captureCaller(3 + l.callerSkip)
+		rec.Caller = captureCaller(4 + l.callerSkip)
 	}
 
 	// Stack trace for error+ levels
 	if lvl >= l.stackLevel {
-		rec.Stack = captureStack(3 + l.callerSkip)
+		rec.Stack = captureStack(4 + l.callerSkip)
 	}
 
 	// Handler errors are intentionally discarded on the hot path.
 	// Use handler-level error callbacks for production error monitoring.
 	_ = l.handler.Handle(rec)
 	releaseRecord(rec)
+}
+
+// terminate finishes a Panic or Fatal call after its record is handled.
+// Panic flushes and panics with msg. Fatal runs the OnFatal hook, or by
+// default flushes and closes the handler, then calls the exit function.
+func (l *Logger) terminate(lvl Level, msg string) {
+	switch lvl {
+	case PanicLevel:
+		_ = l.Flush()
+		panic(msg)
+	case FatalLevel:
+		if l.onFatal != nil {
+			l.onFatal(l)
+		} else {
+			_ = l.Flush()
+			_ = l.Close()
+		}
+		l.Exit(1)
+	}
+}
 
-	if lvl == FatalLevel {
-		os.Exit(1)
+// Exit calls the logger's exit function, os.Exit unless replaced with
+// WithExitFunc.
+func (l *Logger) Exit(code int) {
+	if l.exit != nil {
+		l.exit(code)
+		return
 	}
+	os.Exit(code)
 }
 
 // --- Level methods ---
 
-func (l *Logger) Trace(msg string, kvs ...interface{})   { l.log(TraceLevel, msg, kvs) }
-func (l *Logger) Debug(msg string, kvs ...interface{})   { l.log(DebugLevel, msg, kvs) }
-func (l *Logger) Info(msg string, kvs ...interface{})    { l.log(InfoLevel, msg, kvs) }
-func (l *Logger) Success(msg string, kvs ...interface{}) { l.log(SuccessLevel, msg, kvs) }
-func (l *Logger) Warn(msg string, kvs ...interface{})    { l.log(WarnLevel, msg, kvs) }
-func (l *Logger) Error(msg string, kvs ...interface{})   { l.log(ErrorLevel, msg, kvs) }
-func (l *Logger) Fatal(msg string, kvs ...interface{})   { l.log(FatalLevel, msg, kvs) }
+func (l *Logger) Trace(msg string, kvs ...interface{})   { l.log(l.ctx, TraceLevel, msg, kvs) }
+func (l *Logger) Debug(msg string, kvs ...interface{})   { l.log(l.ctx, DebugLevel, msg, kvs) }
+func (l *Logger) Info(msg string, kvs ...interface{})    { l.log(l.ctx, InfoLevel, msg, kvs) }
+func (l *Logger) Success(msg string, kvs ...interface{}) { l.log(l.ctx, SuccessLevel, msg, kvs) }
+func (l *Logger) Warn(msg string, kvs ...interface{})    { l.log(l.ctx, WarnLevel, msg, kvs) }
+func (l *Logger) Error(msg string, kvs ...interface{})   { l.log(l.ctx, ErrorLevel, msg, kvs) }
+func (l *Logger) Panic(msg string, kvs ...interface{})   { l.log(l.ctx, PanicLevel, msg, kvs) }
+func (l *Logger) Fatal(msg string, kvs ...interface{})   { l.log(l.ctx, FatalLevel, msg, kvs) }
+
+// DPanic logs at ErrorLevel and, in development mode (see
+// WithDevelopment), then panics with msg. Use it for "can't happen"
+// conditions that should fail loudly in tests but not crash production.
+func (l *Logger) DPanic(msg string, kvs ...interface{}) {
+	l.dpanic(l.ctx, msg, kvs)
+}
+
+// --- Context level methods ---
+//
+// These read fields from ctx directly into the record, in place of any
+// context bound with WithContext, without cloning the logger.
+
+func (l *Logger) TraceContext(ctx context.Context, msg string, kvs ...interface{}) {
+	l.log(ctx, TraceLevel, msg, kvs)
+}
+func (l *Logger) DebugContext(ctx context.Context, msg string, kvs ...interface{}) {
+	l.log(ctx, DebugLevel, msg, kvs)
+}
+func (l *Logger) InfoContext(ctx context.Context, msg string, kvs ...interface{}) {
+	l.log(ctx, InfoLevel, msg, kvs)
+}
+func (l *Logger) SuccessContext(ctx context.Context, msg string, kvs ...interface{}) {
+	l.log(ctx, SuccessLevel, msg, kvs)
+}
+func (l *Logger) WarnContext(ctx context.Context, msg string, kvs ...interface{}) {
+	l.log(ctx, WarnLevel, msg, kvs)
+}
+func (l *Logger) ErrorContext(ctx context.Context, msg string, kvs ...interface{}) {
+	l.log(ctx, ErrorLevel, msg, kvs)
+}
+func (l *Logger) PanicContext(ctx context.Context, msg string, kvs ...interface{}) {
+	l.log(ctx, PanicLevel, msg, kvs)
+}
+func (l *Logger) DPanicContext(ctx context.Context, msg string, kvs ...interface{}) {
+	l.dpanic(ctx, msg, kvs)
+}
+func (l *Logger) FatalContext(ctx context.Context, msg string, kvs ...interface{}) {
+	l.log(ctx, FatalLevel, msg, kvs)
+}
+
+// Log logs at lvl, for callers that pick the level at runtime, reading
+// fields from ctx like the methods above. As with the level's own method,
+// PanicLevel panics and FatalLevel exits.
+func (l *Logger) Log(ctx context.Context, lvl Level, msg string, kvs ...interface{}) {
+	l.log(ctx, lvl, msg, kvs)
+}
 
 // Flush flushes the handler if it implements Flusher.
 func (l *Logger) Flush() error {
-	if f, ok := l.handler.(Flusher); ok {
-		return f.Flush()
-	}
-	return nil
+	return flushHandler(l.handler)
 }
 
 // Close closes the handler if it implements Closer.
 func (l *Logger) Close() error {
-	if c, ok := l.handler.(Closer); ok {
-		return c.Close()
-	}
-	return nil
+	return closeHandler(l.handler)
 }
@@ -36,17 +36,84 @@ func Default() *Logger {
 
 // --- Package-level convenience functions ---
 
-func Trace(msg string, kvs ...interface{})   { defaultLogger.Load().log(TraceLevel, msg, kvs) }
-func Debug(msg string, kvs ...interface{})   { defaultLogger.Load().log(DebugLevel, msg, kvs) }
-func Info(msg string, kvs ...interface{})    { defaultLogger.Load().log(InfoLevel, msg, kvs) }
-func Success(msg string, kvs ...interface{}) { defaultLogger.Load().log(SuccessLevel, msg, kvs) }
-func Warn(msg string, kvs ...interface{})    { defaultLogger.Load().log(WarnLevel, msg, kvs) }
-func Error(msg string, kvs ...interface{})   { defaultLogger.Load().log(ErrorLevel, msg, kvs) }
-func Fatal(msg string, kvs ...interface{})   { defaultLogger.Load().log(FatalLevel, msg, kvs) }
+// These log through the default logger, with the fields of any context
+// bound to it by WithContext.
+
+func Trace(msg string, kvs ...interface{}) {
+	l := defaultLogger.Load()
+	l.log(l.ctx, TraceLevel, msg, kvs)
+}
+func Debug(msg string, kvs ...interface{}) {
+	l := defaultLogger.Load()
+	l.log(l.ctx, DebugLevel, msg, kvs)
+}
+func Info(msg string, kvs ...interface{}) {
+	l := defaultLogger.Load()
+	l.log(l.ctx, InfoLevel, msg, kvs)
+}
+func Success(msg string, kvs ...interface{}) {
+	l := defaultLogger.Load()
+	l.log(l.ctx, SuccessLevel, msg, kvs)
+}
+func Warn(msg string, kvs ...interface{}) {
+	l := defaultLogger.Load()
+	l.log(l.ctx, WarnLevel, msg, kvs)
+}
+func Error(msg string, kvs ...interface{}) {
+	l := defaultLogger.Load()
+	l.log(l.ctx, ErrorLevel, msg, kvs)
+}
+func Panic(msg string, kvs ...interface{}) {
+	l := defaultLogger.Load()
+	l.log(l.ctx, PanicLevel, msg, kvs)
+}
+func Fatal(msg string, kvs ...interface{}) {
+	l := defaultLogger.Load()
+	l.log(l.ctx, FatalLevel, msg, kvs)
+}
+
+// DPanic logs at ErrorLevel and panics if the default logger is in
+// development mode. See Logger.DPanic.
+func DPanic(msg string, kvs ...interface{}) {
+	l := defaultLogger.Load()
+	l.dpanic(l.ctx, msg, kvs)
+}
+
+// The XxxContext functions log through the logger stored in ctx by
+// IntoContext, or the default logger, reading fields from ctx.
+
+func TraceContext(ctx context.Context, msg string, kvs ...interface{}) {
+	FromContext(ctx).log(ctx, TraceLevel, msg, kvs)
+}
+func DebugContext(ctx context.Context, msg string, kvs ...interface{}) {
+	FromContext(ctx).log(ctx, DebugLevel, msg, kvs)
+}
+func InfoContext(ctx context.Context, msg string, kvs ...interface{}) {
+	FromContext(ctx).log(ctx, InfoLevel, msg, kvs)
+}
+func SuccessContext(ctx context.Context, msg string, kvs ...interface{}) {
+	FromContext(ctx).log(ctx, SuccessLevel, msg, kvs)
+}
+func WarnContext(ctx context.Context, msg string, kvs ...interface{}) {
+	FromContext(ctx).log(ctx, WarnLevel, msg, kvs)
+}
+func ErrorContext(ctx context.Context, msg string, kvs ...interface{}) {
+	FromContext(ctx).log(ctx, ErrorLevel, msg, kvs)
+}
+func PanicContext(ctx context.Context, msg string, kvs ...interface{}) {
+	FromContext(ctx).log(ctx, PanicLevel, msg, kvs)
+}
+func FatalContext(ctx context.Context, msg string, kvs ...interface{}) {
+	FromContext(ctx).log(ctx, FatalLevel, msg, kvs)
+}
 
 func WithFields(f Fields) *Logger            { return defaultLogger.Load().WithFields(f) }
 func WithContext(ctx context.Context) *Logger { return defaultLogger.Load().WithContext(ctx) }
 func With(fields ...Field) *Logger           { return defaultLogger.Load().With(fields...) }
+func WithGroup(name string) *Logger          { return defaultLogger.Load().WithGroup(name) }
+func Named(name string) *Logger              { return defaultLogger.Load().Named(name) }
+
+func Enabled(lvl Level) bool { return defaultLogger.Load().Enabled(lvl) }
 
 func Flush() error { return defaultLogger.Load().Flush() }
 func Close() error { return defaultLogger.Load().Close() }
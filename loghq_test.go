@@ -3,7 +3,26 @@ package loghq
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -15,8 +34,8 @@ type testWriter struct {
 
 func (w *testWriter) Write(p []byte) (int, error) { return w.buf.Write(p) }
 func (w *testWriter) Sync() error                 { return nil }
-func (w *testWriter) String() string               { return w.buf.String() }
-func (w *testWriter) Reset()                       { w.buf.Reset() }
+func (w *testWriter) String() string              { return w.buf.String() }
+func (w *testWriter) Reset()                      { w.buf.Reset() }
 
 func newTestLogger(w WriteSyncer, handler Handler) *Logger {
 	return New(
@@ -40,6 +59,7 @@ func TestLevelString(t *testing.T) {
 		{SuccessLevel, "OK"},
 		{WarnLevel, "WARN"},
 		{ErrorLevel, "ERROR"},
+		{PanicLevel, "PANIC"},
 		{FatalLevel, "FATAL"},
 	}
 	for _, tt := range tests {
@@ -291,6 +311,265 @@ func TestWithContext(t *testing.T) {
 	}
 }
 
+func TestContextExtractor(t *testing.T) {
+	w := &testWriter{}
+	type tenantKey struct{}
+	logger := New(
+		WithHandler(NewJSONHandler(w)),
+		WithContextExtractor(func(ctx context.Context) []Field {
+			if v, ok := ctx.Value(tenantKey{}).(string); ok {
+				return []Field{String("tenant", v)}
+			}
+			return nil
+		}),
+	)
+
+	ctx := ContextWithFields(context.Background(), String("request_id", "abc-123"))
+	ctx = context.WithValue(ctx, tenantKey{}, "acme")
+	logger.WithContext(ctx).With(Int("k", 1)).Info("processing")
+	out := w.String()
+	if !strings.Contains(out, `"k":1,"request_id":"abc-123","tenant":"acme"`) {
+		t.Errorf("context fields: %s", out)
+	}
+
+	w.Reset()
+	logger.Info("no context")
+	if strings.Contains(w.String(), "tenant") {
+		t.Errorf("extractor ran without a context: %s", w.String())
+	}
+}
+
+func TestWithOptions(t *testing.T) {
+	w := &testWriter{}
+	base := New(WithHandler(NewJSONHandler(w)), WithContextExtractor(W3CTraceExtractor)).With(String("svc", "api"))
+	quiet := base.WithOptions(WithCaller(false), WithContextExtractor(func(context.Context) []Field {
+		return []Field{String("extra", "yes")}
+	}))
+
+	quiet.InfoContext(context.Background(), "q")
+	if out := w.String(); strings.Contains(out, "caller") || !strings.Contains(out, `"svc":"api","extra":"yes"`) {
+		t.Errorf("derived logger: %s", out)
+	}
+
+	w.Reset()
+	base.InfoContext(context.Background(), "b")
+	if out := w.String(); !strings.Contains(out, "caller") || strings.Contains(out, "extra") {
+		t.Errorf("options leaked to parent: %s", out)
+	}
+
+	// The level is copied, not shared.
+	quiet.SetLevel(ErrorLevel)
+	if !base.Enabled(InfoLevel) {
+		t.Error("derived logger changed its parent's level")
+	}
+}
+
+func TestContextMethods(t *testing.T) {
+	w := &testWriter{}
+	logger := New(WithHandler(NewJSONHandler(w)), WithLevel(TraceLevel))
+
+	ctx := ContextWithFields(context.Background(), String("request_id", "abc-123"))
+	methods := map[string]func(context.Context, string, ...interface{}){
+		"TRACE": logger.TraceContext,
+		"DEBUG": logger.DebugContext,
+		"INFO":  logger.InfoContext,
+		"OK":    logger.SuccessContext,
+		"WARN":  logger.WarnContext,
+		"ERROR": logger.ErrorContext,
+	}
+	for level, fn := range methods {
+		w.Reset()
+		fn(ctx, "msg", "k", 1)
+		out := w.String()
+		if !strings.Contains(out, `"level":"`+level+`"`) || !strings.Contains(out, `"request_id":"abc-123","k":1`) {
+			t.Errorf("%s: %s", level, out)
+		}
+		if !strings.Contains(out, "loghq_test.go") {
+			t.Errorf("%s: wrong caller: %s", level, out)
+		}
+	}
+
+	// The passed context replaces one bound with WithContext.
+	w.Reset()
+	bound := logger.WithContext(ContextWithFields(context.Background(), String("bound", "yes")))
+	bound.InfoContext(ctx, "msg")
+	if out := w.String(); strings.Contains(out, "bound") || !strings.Contains(out, "request_id") {
+		t.Errorf("context override: %s", out)
+	}
+
+	bench := newBenchLogger()
+	allocs := testing.AllocsPerRun(100, func() {
+		bench.InfoContext(ctx, "msg", "k", 1)
+	})
+	if allocs != 0 {
+		t.Errorf("InfoContext allocs = %v", allocs)
+	}
+}
+
+func TestLoggerLog(t *testing.T) {
+	w := &testWriter{}
+	logger := New(WithHandler(NewLogfmtHandler(w)))
+	ctx := ContextWithFields(context.Background(), String("req", "r1"))
+
+	logger.Log(ctx, WarnLevel, "picked at runtime", "n", 1)
+	out := w.String()
+	for _, want := range []string{"level=warn", `msg="picked at runtime"`, "req=r1", "n=1", "caller=loghq/loghq_test.go:"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %s in: %s", want, out)
+		}
+	}
+	if v := recoverPanic(func() { logger.Log(ctx, PanicLevel, "boom") }); v != "boom" {
+		t.Errorf("Log at PanicLevel = %v", v)
+	}
+}
+
+func TestLoggerInContext(t *testing.T) {
+	w := &testWriter{}
+	logger := New(WithHandler(NewJSONHandler(w))).With(String("component", "api"))
+
+	if FromContext(context.Background()) != Default() {
+		t.Error("FromContext without a logger should return Default()")
+	}
+	ctx := IntoContext(context.Background(), logger)
+	if FromContext(ctx) != logger {
+		t.Error("FromContext did not return the stored logger")
+	}
+
+	ctx = ContextWithFields(ctx, String("request_id", "abc-123"))
+	InfoContext(ctx, "handled", "status", 200)
+	out := w.String()
+	if !strings.Contains(out, `"component":"api","request_id":"abc-123","status":200`) {
+		t.Errorf("package-level InfoContext: %s", out)
+	}
+	if !strings.Contains(out, "loghq_test.go") {
+		t.Errorf("wrong caller: %s", out)
+	}
+}
+
+func TestDefaultLoggerContext(t *testing.T) {
+	prev := Default()
+	defer SetDefault(prev)
+
+	w := &testWriter{}
+	ctx := ContextWithFields(context.Background(), String("rid", "42"))
+	SetDefault(New(WithHandler(NewLogfmtHandler(w))).WithContext(ctx))
+
+	Info("package level")
+	DPanic("dpanic")
+	for _, line := range strings.Split(strings.TrimSpace(w.String()), "\n") {
+		if !strings.Contains(line, "rid=42") || !strings.Contains(line, "caller=loghq/loghq_test.go:") {
+			t.Errorf("default logger context or caller lost: %s", line)
+		}
+	}
+}
+
+func TestTraceparent(t *testing.T) {
+	const header = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	tc, err := ParseTraceparent(header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !tc.IsValid() || !tc.Sampled() || tc.SpanID[7] != 0xb7 {
+		t.Errorf("parsed %+v", tc)
+	}
+	if got := tc.Traceparent(); got != header {
+		t.Errorf("Traceparent() = %q", got)
+	}
+	if _, err := ParseTraceparent("01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00-extra"); err != nil {
+		t.Errorf("future version: %v", err)
+	}
+
+	for _, bad := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e473g-00f067aa0ba902b7-01",
+		"00_4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+	} {
+		if _, err := ParseTraceparent(bad); err == nil {
+			t.Errorf("ParseTraceparent(%q) succeeded", bad)
+		}
+	}
+}
+
+func TestW3CTraceExtractor(t *testing.T) {
+	w := &testWriter{}
+	logger := New(WithHandler(NewJSONHandler(w)), WithContextExtractor(W3CTraceExtractor))
+
+	tc, _ := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	ctx := ContextWithSpanContext(context.Background(), tc)
+	if got, ok := SpanContextFromContext(ctx); !ok || got != tc {
+		t.Errorf("SpanContextFromContext = %+v, %v", got, ok)
+	}
+
+	traced := logger.WithContext(ctx)
+	traced.Info("charged")
+	want := `"trace_id":"4bf92f3577b34da6a3ce929d0e0e4736","span_id":"00f067aa0ba902b7","trace_flags":"01"`
+	if !strings.Contains(w.String(), want) {
+		t.Errorf("trace fields: %s", w.String())
+	}
+
+	w.Reset()
+	logger.WithContext(context.Background()).Info("untraced")
+	if strings.Contains(w.String(), "trace_id") {
+		t.Errorf("trace fields without trace: %s", w.String())
+	}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		W3CTraceExtractor(ctx)
+	})
+	if allocs != 0 {
+		t.Errorf("W3CTraceExtractor allocs = %v", allocs)
+	}
+
+	// An adapter for a foreign span context, as used with OpenTelemetry.
+	type spanKey struct{}
+	ext := TraceExtractor(func(ctx context.Context) (SpanContext, bool) {
+		tc, ok := ctx.Value(spanKey{}).(SpanContext)
+		return tc, ok
+	})
+	if f := ext(context.WithValue(context.Background(), spanKey{}, tc)); len(f) != 3 || f[1].Str != "00f067aa0ba902b7" {
+		t.Errorf("TraceExtractor fields = %+v", f)
+	}
+	if f := ext(context.WithValue(context.Background(), spanKey{}, SpanContext{})); f != nil {
+		t.Errorf("invalid trace context produced %+v", f)
+	}
+}
+
+func TestRecordClone(t *testing.T) {
+	rec := acquireRecord()
+	rec.Message = "m"
+	rec.Caller = newCallerInfo("/src/app/main.go", 7, "main.main")
+	rec.Stack = "stack"
+	raw := []byte("abc")
+	for i := 0; i < inlineFieldCap; i++ {
+		rec.AddField(Int("n", i))
+	}
+	rec.AddField(Bytes("raw", raw))
+	rec.AddField(Group("g", String("k", "v")))
+
+	c := rec.Clone()
+	raw[0] = 'X'
+	rec.FieldAt(0).Ival = 99
+	rec.FieldAt(inlineFieldCap + 1).Iface.([]Field)[0].Str = "changed"
+	releaseRecord(rec)
+
+	if c.Message != "m" || c.Caller.String() != "app/main.go:7" || c.Stack != "stack" || c.NumFields() != inlineFieldCap+2 {
+		t.Fatalf("clone = %+v", c)
+	}
+	if c.FieldAt(0).Ival != 0 {
+		t.Error("inline field shared")
+	}
+	if f, _ := c.Lookup("raw"); string(f.Iface.([]byte)) != "abc" {
+		t.Error("bytes shared")
+	}
+	if f, _ := c.Lookup("g"); f.Iface.([]Field)[0].Str != "v" {
+		t.Error("group shared")
+	}
+}
+
 func TestMultiHandler(t *testing.T) {
 	w1 := &testWriter{}
 	w2 := &testWriter{}
@@ -329,6 +608,177 @@ func TestCallerCapture(t *testing.T) {
 	}
 }
 
+func TestCallerRendering(t *testing.T) {
+	tests := []struct {
+		file, fn, want string
+	}{
+		{"/home/ci/src/loghq/logger.go", "github.com/Bhavyyadav25/loghq.(*Logger).Info", "loghq/logger.go"},
+		{"/root/go/pkg/mod/github.com/acme/app@v1.2.0/payments/ledger.go", "github.com/acme/app/payments.Post", "payments/ledger.go"},
+		{"/tmp/checkout/ledger.go", "github.com/acme/app/v2.Post", "app/ledger.go"},
+		{"/tmp/checkout/svc.go", "example.com/v2.Run", "example.com/svc.go"},
+		{"/src/cmd/server/main.go", "main.main", "server/main.go"},
+		{"/src/app/http/server.go", "app/http.serve.func1", "http/server.go"},
+		{"/src/loghq/example_test.go", "github.com/Bhavyyadav25/loghq_test.Example", "loghq/example_test.go"},
+		{"/go/pkg/mod/gopkg.in/yaml.v3@v3.0.1/decode.go", "gopkg.in/yaml%2ev3.(*parser).parse", "yaml.v3/decode.go"},
+	}
+	for _, tt := range tests {
+		if got := newCallerInfo(tt.file, 1, tt.fn).File; got != tt.want {
+			t.Errorf("newCallerInfo(%q, %q).File = %q, want %q", tt.file, tt.fn, got, tt.want)
+		}
+	}
+	if pkg := newCallerInfo("decode.go", 1, "gopkg.in/yaml%2ev3.Unmarshal").Package; pkg != "gopkg.in/yaml.v3" {
+		t.Errorf("Package = %q, want gopkg.in/yaml.v3", pkg)
+	}
+}
+
+func TestClock(t *testing.T) {
+	w := &testWriter{}
+	fixed := time.Date(2025, 1, 30, 14, 32, 1, 0, time.UTC)
+	logger := New(WithHandler(NewJSONHandler(w)), WithClock(ClockFunc(func() time.Time { return fixed })))
+	logger.Named("child").With(Int("k", 1)).Info("one")
+	if !strings.HasPrefix(w.String(), `{"time":"2025-01-30T14:32:01Z"`) {
+		t.Errorf("clock not used by derived logger: %s", w.String())
+	}
+}
+
+// closeRecorder records Flush and Close calls in order.
+type closeRecorder struct {
+	Handler
+	calls *[]string
+}
+
+func (c closeRecorder) Flush() error { *c.calls = append(*c.calls, "flush"); return nil }
+func (c closeRecorder) Close() error { *c.calls = append(*c.calls, "close"); return nil }
+
+func TestFatalExit(t *testing.T) {
+	w := &testWriter{}
+	var calls []string
+	async := NewAsyncHandler(NewJSONHandler(w))
+	logger := New(
+		WithHandler(closeRecorder{Handler: async, calls: &calls}),
+		WithExitFunc(func(code int) { calls = append(calls, "exit "+strconv.Itoa(code)) }),
+	)
+
+	logger.Fatal("giving up", "reason", "disk full")
+	async.Close()
+	if got := strings.Join(calls, ","); got != "flush,close,exit 1" {
+		t.Errorf("calls = %s", got)
+	}
+	if !strings.Contains(w.String(), `"level":"FATAL","msg":"giving up"`) {
+		t.Errorf("fatal record: %s", w.String())
+	}
+
+	// Filtered out, Fatal still exits.
+	calls = nil
+	logger.SetLevel(FatalLevel + 1)
+	logger.Fatal("quiet")
+	if got := strings.Join(calls, ","); got != "flush,close,exit 1" {
+		t.Errorf("filtered calls = %s", got)
+	}
+}
+
+func TestOnFatal(t *testing.T) {
+	w := &testWriter{}
+	var hooked *Logger
+	var exited []int
+	logger := New(
+		WithHandler(NewJSONHandler(w)),
+		WithOnFatal(func(l *Logger) { hooked = l }),
+		WithExitFunc(func(code int) { exited = append(exited, code) }),
+	)
+	child := logger.Named("db")
+	child.Fatal("unreachable")
+	if hooked != child {
+		t.Error("OnFatal did not receive the logging logger")
+	}
+	if len(exited) != 1 || exited[0] != 1 {
+		t.Errorf("a returning OnFatal hook must still exit with 1, got %v", exited)
+	}
+	if !strings.Contains(w.String(), "unreachable") {
+		t.Errorf("fatal record: %s", w.String())
+	}
+
+	// Filtered out, the hook and the exit each still run exactly once.
+	hooked, exited = nil, nil
+	hooks := 0
+	quiet := logger.WithOptions(WithLevel(FatalLevel+1), WithOnFatal(func(*Logger) { hooks++ }))
+	quiet.FatalContext(context.Background(), "quiet")
+	if hooks != 1 || len(exited) != 1 {
+		t.Errorf("filtered Fatal ran the hook %d times and exited %v", hooks, exited)
+	}
+}
+
+// recoverPanic runs fn and returns the value it panicked with, or nil.
+func recoverPanic(fn func()) (v interface{}) {
+	defer func() { v = recover() }()
+	fn()
+	return nil
+}
+
+func TestPanicLevel(t *testing.T) {
+	w := &testWriter{}
+	logger := New(WithHandler(NewLogfmtHandler(w)), WithStackLevel(FatalLevel+1))
+
+	if v := recoverPanic(func() { logger.Panic("corrupt state", "id", 7) }); v != "corrupt state" {
+		t.Errorf("panic value = %v", v)
+	}
+	if !strings.Contains(w.String(), `level=panic msg="corrupt state"`) || !strings.Contains(w.String(), "id=7") {
+		t.Errorf("panic record: %s", w.String())
+	}
+
+	w.Reset()
+	logger.SetLevel(PanicLevel + 1)
+	if v := recoverPanic(func() { logger.PanicContext(context.Background(), "filtered") }); v != "filtered" {
+		t.Errorf("filtered Panic did not panic: %v", v)
+	}
+	if w.String() != "" {
+		t.Errorf("filtered record written: %s", w.String())
+	}
+
+	var lvl Level
+	if err := lvl.UnmarshalText([]byte("panic")); err != nil || lvl != PanicLevel || ParseLevel("PANIC") != PanicLevel {
+		t.Errorf("parse panic = %v, %v", lvl, err)
+	}
+	if FatalLevel != 4 || PanicLevel.String() != "PANIC" {
+		t.Errorf("FatalLevel = %d, PanicLevel = %s", FatalLevel, PanicLevel)
+	}
+	if SyslogSeverity(PanicLevel) != 2 {
+		t.Errorf("SyslogSeverity(PanicLevel) = %d", SyslogSeverity(PanicLevel))
+	}
+}
+
+func TestDPanic(t *testing.T) {
+	w := &testWriter{}
+	prod := New(WithHandler(NewLogfmtHandler(w)), WithStackLevel(FatalLevel+1))
+	if v := recoverPanic(func() { prod.DPanic("impossible", "n", -1) }); v != nil {
+		t.Errorf("production DPanic panicked: %v", v)
+	}
+	if !strings.Contains(w.String(), "level=error msg=impossible") {
+		t.Errorf("DPanic record: %s", w.String())
+	}
+
+	dev := prod.WithOptions(WithDevelopment(true))
+	if v := recoverPanic(func() { dev.DPanic("impossible") }); v != "impossible" {
+		t.Errorf("development DPanic = %v", v)
+	}
+	if v := recoverPanic(func() { dev.DPanicContext(context.Background(), "again") }); v != "again" {
+		t.Errorf("development DPanicContext = %v", v)
+	}
+
+	// The record names the DPanic call site, and a filtered DPanic still
+	// panics in development mode.
+	w.Reset()
+	_, _, line, _ := runtime.Caller(0)
+	recoverPanic(func() { dev.WithOptions(WithCaller(true)).DPanic("here") })
+	if want := "loghq/loghq_test.go:" + strconv.Itoa(line+1); !strings.Contains(w.String(), want) {
+		t.Errorf("DPanic caller: want %s in %s", want, w.String())
+	}
+	dev.SetLevel(FatalLevel)
+	if v := recoverPanic(func() { dev.DPanic("filtered") }); v != "filtered" {
+		t.Errorf("filtered development DPanic = %v", v)
+	}
+}
+
 func TestDurationField(t *testing.T) {
 	w := &testWriter{}
 	h := NewJSONHandler(w)
@@ -359,3 +809,2122 @@ func TestSetLevel(t *testing.T) {
 		t.Error("should be visible after level change")
 	}
 }
+
+// --- slog adapter tests ---
+
+func TestSlogLevelRoundTrip(t *testing.T) {
+	for lvl := TraceLevel; lvl <= PanicLevel; lvl++ {
+		if got := LevelFromSlog(SlogLevel(lvl)); got != lvl {
+			t.Errorf("round trip %s: got %s", lvl, got)
+		}
+	}
+	if LevelFromSlog(slog.LevelWarn+1) != WarnLevel {
+		t.Error("intermediate slog level should round down")
+	}
+}
+
+func TestSlogBridge(t *testing.T) {
+	w := &testWriter{}
+	sl := NewSlogLogger(NewJSONHandler(w))
+
+	sl.With("service", "api").WithGroup("http").Info("request",
+		"method", "GET",
+		slog.Group("resp", slog.Int("status", 200)),
+	)
+
+	out := w.String()
+	for _, want := range []string{
+		`"level":"INFO"`,
+		`"msg":"request"`,
+		`"service":"api"`,
+		`"http":{"method":"GET","resp":{"status":200}}`,
+		`loghq_test.go:`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %s in: %s", want, out)
+		}
+	}
+}
+
+func TestSlogHandler(t *testing.T) {
+	var buf bytes.Buffer
+	sh := slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: SlogLevelTrace})
+	logger := newTestLogger(nil, NewSlogHandler(sh))
+
+	logger.Success("migrated", "tables", 12)
+
+	out := buf.String()
+	if !strings.Contains(out, `"msg":"migrated"`) || !strings.Contains(out, `"tables":12`) {
+		t.Errorf("unexpected slog output: %s", out)
+	}
+	if !strings.Contains(out, `"level":"INFO+2"`) {
+		t.Errorf("success level not preserved: %s", out)
+	}
+}
+
+func TestSlogHandlerSource(t *testing.T) {
+	var buf bytes.Buffer
+	sh := slog.NewJSONHandler(&buf, &slog.HandlerOptions{AddSource: true})
+	logger := New(WithHandler(NewSlogHandler(sh)), WithStackLevel(FatalLevel+1))
+
+	logger.Info("sourced")
+
+	var rec struct {
+		Source slog.Source `json:"source"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &rec); err != nil {
+		t.Fatalf("invalid JSON %s: %v", buf.String(), err)
+	}
+	if !strings.HasSuffix(rec.Source.File, "loghq_test.go") || rec.Source.Function != "github.com/Bhavyyadav25/loghq.TestSlogHandlerSource" || rec.Source.Line == 0 {
+		t.Errorf("source = %+v", rec.Source)
+	}
+}
+
+// slogCtxHandler records the context each record is handled with.
+type slogCtxHandler struct {
+	slog.Handler
+	ctx *context.Context
+}
+
+func (h slogCtxHandler) Handle(ctx context.Context, r slog.Record) error {
+	*h.ctx = ctx
+	return h.Handler.Handle(ctx, r)
+}
+
+func TestSlogHandlerFieldsAndContext(t *testing.T) {
+	type ctxKey struct{}
+	var buf bytes.Buffer
+	var got context.Context
+	sh := slogCtxHandler{slog.NewJSONHandler(&buf, nil), &got}
+	logger := newTestLogger(nil, NewSlogHandler(sh))
+
+	ctx := context.WithValue(context.Background(), ctxKey{}, "r1")
+	logger.InfoContext(ctx, "typed",
+		"user", &testUser{Name: "ali", ID: 42, Roles: testRoles{"admin"}},
+		"roles", testRoles{"a", "b"},
+		"z", complex(1.5, -2),
+	)
+
+	out := buf.String()
+	for _, want := range []string{
+		`"user":{"name":"ali","id":42,"roles":"[\"admin\"]"}`,
+		`"roles":"[\"a\",\"b\"]"`,
+		`"z":"1.5-2i"`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %s in: %s", want, out)
+		}
+	}
+	if got == nil || got.Value(ctxKey{}) != "r1" {
+		t.Error("record context not passed to the slog handler")
+	}
+}
+
+// --- Async handler tests ---
+
+// gatedHandler blocks in Handle until release is closed.
+type gatedHandler struct {
+	release chan struct{}
+	mu      sync.Mutex
+	msgs    []string
+}
+
+func (h *gatedHandler) Enabled(Level) bool { return true }
+func (h *gatedHandler) Handle(rec *Record) error {
+	<-h.release
+	h.mu.Lock()
+	h.msgs = append(h.msgs, rec.Message)
+	h.mu.Unlock()
+	return nil
+}
+
+func TestAsyncHandlerFlush(t *testing.T) {
+	w := &testWriter{}
+	a := NewAsyncHandler(NewJSONHandler(w))
+	logger := newTestLogger(w, a)
+
+	for i := 0; i < 100; i++ {
+		logger.Info("queued", "i", i)
+	}
+	if err := logger.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(w.String(), `"msg":"queued"`); n != 100 {
+		t.Errorf("expected 100 records after Flush, got %d", n)
+	}
+	if !strings.Contains(w.String(), `"i":99`) {
+		t.Errorf("record fields not copied: %s", w.String())
+	}
+	if err := a.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Handle(acquireRecord()); err != ErrHandlerClosed {
+		t.Errorf("Handle after Close: %v", err)
+	}
+}
+
+func TestAsyncHandlerOverflow(t *testing.T) {
+	tests := []struct {
+		name string
+		opt  AsyncOption
+		want []string
+	}{
+		{"drop newest", WithAsyncOverflow(OverflowDropNewest), []string{"0", "1", "2"}},
+		{"drop oldest", WithAsyncOverflow(OverflowDropOldest), []string{"0", "3", "4"}},
+		{"drop below", WithAsyncDropBelow(WarnLevel), []string{"0", "1", "2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &gatedHandler{release: make(chan struct{})}
+			a := NewAsyncHandler(h, WithAsyncQueueSize(2), tt.opt)
+			logger := newTestLogger(nil, a)
+
+			logger.Info("0")
+			// Wait for the worker to pick up the first record and block.
+			for a.Len() != 0 {
+				runtime.Gosched()
+			}
+			for _, m := range []string{"1", "2", "3", "4"} {
+				logger.Info(m)
+			}
+			close(h.release)
+			a.Close()
+
+			if got := strings.Join(h.msgs, ","); got != strings.Join(tt.want, ",") {
+				t.Errorf("handled %s, want %v", got, tt.want)
+			}
+			if a.Dropped() != 2 {
+				t.Errorf("dropped = %d, want 2", a.Dropped())
+			}
+		})
+	}
+}
+
+// --- Sampling handler tests ---
+
+func TestSamplingHandler(t *testing.T) {
+	w := &testWriter{}
+	s := NewSamplingHandler(NewLogfmtHandler(w), time.Minute, 3, 5)
+	logger := newTestLogger(w, s)
+
+	for i := 0; i < 20; i++ {
+		logger.Info("hot loop")
+		logger.Warn("hot loop") // separate bucket per level
+	}
+
+	// n = 1, 2, 3, 8, 13, 18 pass for each level.
+	if n := strings.Count(w.String(), "level=info"); n != 6 {
+		t.Errorf("info records passed = %d, want 6", n)
+	}
+	if n := strings.Count(w.String(), "level=warn"); n != 6 {
+		t.Errorf("warn records passed = %d, want 6", n)
+	}
+	if s.Dropped() != 28 {
+		t.Errorf("dropped = %d, want 28", s.Dropped())
+	}
+}
+
+func TestSamplingHandlerSummary(t *testing.T) {
+	w := &testWriter{}
+	s := NewSamplingHandler(NewLogfmtHandler(w), time.Second, 1, 0, WithSamplingSummary())
+	defer s.Close()
+
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, offset := range []time.Duration{0, 100 * time.Millisecond, 200 * time.Millisecond, 2 * time.Second} {
+		rec := acquireRecord()
+		rec.Time = start.Add(offset)
+		rec.Message = "retrying"
+		rec.AddField(Int("attempt", i))
+		s.Handle(rec)
+		releaseRecord(rec)
+	}
+
+	lines := strings.Split(strings.TrimSpace(w.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 lines, got %d: %s", len(lines), w.String())
+	}
+	if !strings.Contains(lines[1], "msg=retrying suppressed=2") {
+		t.Errorf("missing summary: %s", lines[1])
+	}
+	if !strings.Contains(lines[2], "attempt=3") {
+		t.Errorf("record after rollover should pass: %s", lines[2])
+	}
+}
+
+// lockedWriter is a testWriter safe for concurrent use.
+type lockedWriter struct {
+	mu sync.Mutex
+	w  testWriter
+}
+
+func (l *lockedWriter) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.w.Write(p)
+}
+
+func (l *lockedWriter) Sync() error { return nil }
+
+func (l *lockedWriter) String() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.w.String()
+}
+
+func TestSamplingHandlerSummarySweep(t *testing.T) {
+	w := &lockedWriter{}
+	s := NewSamplingHandler(NewLogfmtHandler(w), 10*time.Millisecond, 1, 0, WithSamplingSummary())
+	defer s.Close()
+	logger := newTestLogger(w, s)
+
+	// The message never recurs, so only the sweep can report the drops.
+	for i := 0; i < 5; i++ {
+		logger.Warn("burst")
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for !strings.Contains(w.String(), "msg=burst suppressed=4") {
+		if time.Now().After(deadline) {
+			t.Fatalf("no summary from sweep: %s", w.String())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if !strings.Contains(w.String(), "level=warn msg=burst suppressed=4") {
+		t.Errorf("summary level: %s", w.String())
+	}
+}
+
+func TestSamplingHandlerSummaryOnClose(t *testing.T) {
+	w := &lockedWriter{}
+	s := NewSamplingHandler(NewLogfmtHandler(w), time.Hour, 2, 0, WithSamplingSummary())
+	logger := newTestLogger(w, s)
+	for i := 0; i < 5; i++ {
+		logger.Info("flood")
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(w.String(), "msg=flood suppressed=3") {
+		t.Errorf("pending summary not written on Close: %s", w.String())
+	}
+	s.Close() // idempotent
+}
+
+// --- Redacting handler tests ---
+
+func TestRedactingHandler(t *testing.T) {
+	w := &testWriter{}
+	h := NewRedactingHandler(NewJSONHandler(w),
+		RedactKeys(RedactFull, "password"),
+		RedactKeyGlob(RedactPartial, "*_card"),
+		RedactKeys(RedactHash, "user_id"),
+		RedactPattern(RedactFull, regexp.MustCompile(`[\w.]+@[\w.]+`)),
+		WithRedactSalt([]byte("pepper")),
+	)
+	logger := newTestLogger(w, h).With(String("password", "hunter2"))
+
+	ctx := ContextWithFields(context.Background(), Int("user_id", 42))
+	logger.WithContext(ctx).Info("signup",
+		"credit_card", "4111111111111111",
+		"note", "contact ali@example.com today",
+		"plan", "pro",
+	)
+
+	sum := sha256.Sum256([]byte("pepper42"))
+	out := w.String()
+	for _, want := range []string{
+		`"password":"[REDACTED]"`,
+		`"credit_card":"************1111"`,
+		`"user_id":"sha256:` + hex.EncodeToString(sum[:]) + `"`,
+		`"note":"contact [REDACTED] today"`,
+		`"plan":"pro"`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %s in: %s", want, out)
+		}
+	}
+}
+
+func TestRedactingHandlerDoesNotMutateRecord(t *testing.T) {
+	w1, w2 := &testWriter{}, &testWriter{}
+	h := NewMultiHandler(
+		NewRedactingHandler(NewLogfmtHandler(w1), RedactKeys(RedactFull, "token")),
+		NewLogfmtHandler(w2),
+	)
+	newTestLogger(nil, h).Info("auth", "token", "secret")
+
+	if !strings.Contains(w1.String(), "token=[REDACTED]") {
+		t.Errorf("redacted output: %s", w1.String())
+	}
+	if !strings.Contains(w2.String(), "token=secret") {
+		t.Errorf("sibling handler saw redacted record: %s", w2.String())
+	}
+}
+
+func TestRedactingHandlerGroupKey(t *testing.T) {
+	w := &testWriter{}
+	h := NewRedactingHandler(NewJSONHandler(w), RedactKeys(RedactFull, "credentials"))
+	newTestLogger(w, h).Info("login", "credentials", Group("credentials", String("user", "ali"), String("pass", "hunter2")))
+
+	if out := w.String(); !strings.Contains(out, `"credentials":"[REDACTED]"`) || strings.Contains(out, "hunter2") {
+		t.Errorf("group not redacted: %s", out)
+	}
+}
+
+func TestRedactingHandlerObjectMembers(t *testing.T) {
+	user := ObjectMarshalerFunc(func(enc FieldEncoder) error {
+		enc.EncodeString("name", "ali")
+		enc.EncodeString("password", "hunter2")
+		enc.EncodeArray("keys", ArrayMarshalerFunc(func(enc ArrayEncoder) error {
+			enc.AppendObject(ObjectMarshalerFunc(func(enc FieldEncoder) error {
+				enc.EncodeString("password", "s3cret")
+				enc.EncodeInt64("id", 7)
+				return nil
+			}))
+			return nil
+		}))
+		return nil
+	})
+
+	tests := []struct {
+		name string
+		h    func(w WriteSyncer) Handler
+		want string
+	}{
+		{"json", func(w WriteSyncer) Handler { return NewJSONHandler(w) },
+			`"user":{"name":"ali","password":"[REDACTED]","keys":[{"password":"[REDACTED]","id":7}]}`},
+		{"logfmt", func(w WriteSyncer) Handler { return NewLogfmtHandler(w) },
+			`user.name=ali user.password=[REDACTED] user.keys=[{password=[REDACTED],id=7}]`},
+		{"console", func(w WriteSyncer) Handler {
+			return NewConsoleHandler(WithConsoleWriter(w), WithConsoleNoColor())
+		}, `user.name=ali user.password=[REDACTED] user.keys=[{password=[REDACTED],id=7}]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &testWriter{}
+			h := NewRedactingHandler(tt.h(w), RedactKeys(RedactFull, "password"))
+			newTestLogger(w, h).Info("signup", "user", Object("user", user))
+			if !strings.Contains(w.String(), tt.want) {
+				t.Errorf("got %s\nwant %s", w.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactingHandlerNamespace(t *testing.T) {
+	w := &testWriter{}
+	h := NewRedactingHandler(NewJSONHandler(w), RedactKeyGlob(RedactFull, "auth*"))
+	newTestLogger(w, h).WithGroup("authz").Info("check", "user", "bob", "role", "admin", "auth_token", "t0k3n")
+
+	out := w.String()
+	if !strings.Contains(out, `"authz":{"user":"bob","role":"admin","auth_token":"[REDACTED]"}`) {
+		t.Errorf("namespace redacted or members moved out of it: %s", out)
+	}
+}
+
+func TestRedactingHandlerObjectNoMatchAllocs(t *testing.T) {
+	user := ObjectMarshalerFunc(func(enc FieldEncoder) error {
+		enc.EncodeString("name", "ali")
+		enc.EncodeInt64("id", 42)
+		return nil
+	})
+	h := NewRedactingHandler(discardHandler{}, RedactKeys(RedactFull, "password"))
+	rec := acquireRecord()
+	defer releaseRecord(rec)
+	rec.AddField(Object("user", user))
+	rec.AddField(Array("roles", testRoles{"admin", "dev"}))
+
+	if n := testing.AllocsPerRun(100, func() { h.Handle(rec) }); n != 0 {
+		t.Errorf("checking objects with no matching member allocated %v times", n)
+	}
+}
+
+func TestRedactPartialRunes(t *testing.T) {
+	w := &testWriter{}
+	h := NewRedactingHandler(NewJSONHandler(w), RedactKeys(RedactPartial, "name", "short"))
+	newTestLogger(w, h).Info("x", "name", "Zoë Ångström", "short", "äö")
+
+	out := w.String()
+	for _, want := range []string{`"name":"********tröm"`, `"short":"**"`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %s in: %s", want, out)
+		}
+	}
+}
+
+// credentialError carries a secret in its LogFields.
+type credentialError struct{ err error }
+
+func (e credentialError) Error() string { return "login failed" }
+func (e credentialError) Unwrap() error { return e.err }
+func (e credentialError) LogFields() []Field {
+	return []Field{String("user", "ali"), String("password", "hunter2")}
+}
+
+func TestRedactingHandlerErrorDetails(t *testing.T) {
+	err := fmt.Errorf("signup: %w", credentialError{errors.New("mail to ali@example.com bounced")})
+
+	tests := []struct {
+		name string
+		h    func(w WriteSyncer) Handler
+		want []string
+	}{
+		{"json", func(w WriteSyncer) Handler { return NewJSONHandler(w) },
+			[]string{`"password":"[REDACTED]"`, `"msg":"mail to [REDACTED] bounced"`, `"user":"ali"`}},
+		{"logfmt", func(w WriteSyncer) Handler { return NewLogfmtHandler(w) },
+			[]string{"password=[REDACTED]", `"mail to [REDACTED] bounced"`}},
+		{"console", func(w WriteSyncer) Handler {
+			return NewConsoleHandler(WithConsoleWriter(w), WithConsoleNoColor())
+		}, []string{"password=[REDACTED]", "mail to [REDACTED] bounced"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &testWriter{}
+			h := NewRedactingHandler(tt.h(w),
+				RedactKeys(RedactFull, "password"),
+				RedactPattern(RedactFull, regexp.MustCompile(`[\w.]+@[\w.]+`)),
+			)
+			newTestLogger(w, h).Error("failed", "error", err)
+			out := w.String()
+			for _, want := range tt.want {
+				if !strings.Contains(out, want) {
+					t.Errorf("missing %s in: %s", want, out)
+				}
+			}
+			if strings.Contains(out, "hunter2") || strings.Contains(out, "example.com") {
+				t.Errorf("secret leaked: %s", out)
+			}
+		})
+	}
+}
+
+// --- Group tests ---
+
+func TestGroups(t *testing.T) {
+	tests := []struct {
+		name string
+		h    func(w WriteSyncer) Handler
+		want string
+	}{
+		{"json", func(w WriteSyncer) Handler { return NewJSONHandler(w) },
+			`"svc":"api","http":{"method":"GET","resp":{"status":200,"ok":true},"path":"/"}}`},
+		{"logfmt", func(w WriteSyncer) Handler { return NewLogfmtHandler(w) },
+			`svc=api http.method=GET http.resp.status=200 http.resp.ok=true http.path=/`},
+		{"console", func(w WriteSyncer) Handler {
+			return NewConsoleHandler(WithConsoleWriter(w), WithConsoleNoColor())
+		}, `req  svc=api http.method=GET http.resp.status=200 http.resp.ok=true http.path=/`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &testWriter{}
+			logger := newTestLogger(w, tt.h(w)).With(String("svc", "api")).WithGroup("http")
+			logger.Info("req", "method", "GET",
+				"resp", Group("resp", Int("status", 200), Bool("ok", true)),
+				"path", "/")
+			if !strings.Contains(w.String(), tt.want) {
+				t.Errorf("got %s\nwant %s", w.String(), tt.want)
+			}
+		})
+	}
+}
+
+// --- JSON Any encoding tests ---
+
+type jsonPoint struct {
+	X, Y int
+}
+
+type jsonMarshaled struct{}
+
+func (jsonMarshaled) MarshalJSON() ([]byte, error) { return []byte(`{"custom":true}`), nil }
+
+func TestJSONEncoderAny(t *testing.T) {
+	w := &testWriter{}
+	logger := newTestLogger(w, NewJSONHandler(w))
+
+	logger.Info("composite",
+		"tags", []string{"a", "b"},
+		"ids", []int{1, 2},
+		"attrs", map[string]interface{}{"b": 1.5, "a": []interface{}{"x", true, nil}},
+		"point", jsonPoint{X: 1, Y: 2},
+		"ptr", &jsonPoint{X: 3},
+		"marshaler", jsonMarshaled{},
+		"ip", net.IPv4(10, 0, 0, 1),
+	)
+
+	out := w.String()
+	for _, want := range []string{
+		`"tags":["a","b"]`,
+		`"ids":[1,2]`,
+		`"attrs":{"a":["x",true,null],"b":1.5}`,
+		`"point":{"X":1,"Y":2}`,
+		`"ptr":{"X":3,"Y":0}`,
+		`"marshaler":{"custom":true}`,
+		`"ip":"10.0.0.1"`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %s in: %s", want, out)
+		}
+	}
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal([]byte(out), &decoded); err != nil {
+		t.Fatalf("output is not valid JSON: %v\n%s", err, out)
+	}
+}
+
+type jsonIndented struct{ A, B int }
+
+func (v jsonIndented) MarshalJSON() ([]byte, error) {
+	return json.MarshalIndent(struct{ A, B int }(v), "", "  ")
+}
+
+type jsonPanics struct{}
+
+func (jsonPanics) MarshalJSON() ([]byte, error) { panic("boom") }
+
+func TestJSONEncoderAnyMarshalerEdgeCases(t *testing.T) {
+	w := &testWriter{}
+	logger := newTestLogger(w, NewJSONHandler(w))
+
+	var nilPtr *jsonMarshaled
+	logger.Info("marshalers",
+		"nil", nilPtr,
+		"indented", jsonIndented{A: 1, B: 2},
+		"panics", jsonPanics{},
+	)
+
+	out := w.String()
+	for _, want := range []string{
+		`"nil":null`,
+		`"indented":{"A":1,"B":2}`,
+		`"panics":"<PANIC=boom>"`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %s in: %s", want, out)
+		}
+	}
+	if strings.Count(out, "\n") != 1 {
+		t.Errorf("record spans several lines: %q", out)
+	}
+}
+
+// --- ObjectMarshaler / ArrayMarshaler tests ---
+
+type testUser struct {
+	Name  string
+	ID    int64
+	Roles testRoles
+}
+
+func (u *testUser) MarshalLogObject(enc FieldEncoder) error {
+	enc.EncodeString("name", u.Name)
+	enc.EncodeInt64("id", u.ID)
+	enc.EncodeArray("roles", u.Roles)
+	return nil
+}
+
+type testRoles []string
+
+func (r testRoles) MarshalLogArray(enc ArrayEncoder) error {
+	for _, role := range r {
+		enc.AppendString(role)
+	}
+	return nil
+}
+
+type testUsers []*testUser
+
+func (us testUsers) MarshalLogArray(enc ArrayEncoder) error {
+	for _, u := range us {
+		enc.AppendObject(u)
+	}
+	return nil
+}
+
+func TestObjectMarshaler(t *testing.T) {
+	u := &testUser{Name: "ali", ID: 42, Roles: testRoles{"admin", "dev"}}
+	team := testUsers{u, {Name: "sara", ID: 7}}
+
+	tests := []struct {
+		name string
+		h    func(w WriteSyncer) Handler
+		want string
+	}{
+		{"json", func(w WriteSyncer) Handler { return NewJSONHandler(w) },
+			`"user":{"name":"ali","id":42,"roles":["admin","dev"]},"team":[{"name":"ali","id":42,"roles":["admin","dev"]},{"name":"sara","id":7,"roles":[]}]`},
+		{"logfmt", func(w WriteSyncer) Handler { return NewLogfmtHandler(w) },
+			`user.name=ali user.id=42 user.roles=[admin,dev] team=[{name=ali,id=42,roles=[admin,dev]},{name=sara,id=7,roles=[]}]`},
+		{"console", func(w WriteSyncer) Handler {
+			return NewConsoleHandler(WithConsoleWriter(w), WithConsoleNoColor())
+		}, `user.name=ali user.id=42 user.roles=[admin,dev] team=[{name=ali,id=42,roles=[admin,dev]},{name=sara,id=7,roles=[]}]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &testWriter{}
+			logger := newTestLogger(w, tt.h(w))
+			logger.Info("login", "user", u, "team", team)
+			if !strings.Contains(w.String(), tt.want) {
+				t.Errorf("got %s\nwant %s", w.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestObjectMarshalerError(t *testing.T) {
+	w := &testWriter{}
+	logger := newTestLogger(w, NewJSONHandler(w))
+	logger.With(Object("obj", ObjectMarshalerFunc(func(enc FieldEncoder) error {
+		enc.EncodeBool("partial", true)
+		return errors.New("boom")
+	}))).Info("bad")
+	if !strings.Contains(w.String(), `"obj":{"partial":true,"error":"boom"}`) {
+		t.Errorf("marshal error not recorded: %s", w.String())
+	}
+}
+
+func TestObjectMarshalerAllocs(t *testing.T) {
+	u := &testUser{Name: "ali", ID: 42}
+	for name, h := range map[string]Handler{
+		"json":    NewJSONHandler(discardWriteSyncer{}),
+		"logfmt":  NewLogfmtHandler(discardWriteSyncer{}),
+		"console": NewConsoleHandler(WithConsoleWriter(discardWriteSyncer{})),
+	} {
+		logger := newTestLogger(nil, h)
+		if n := testing.AllocsPerRun(100, func() { logger.Info("login", "user", u) }); n != 0 {
+			t.Errorf("%s: logging an object allocated %v times", name, n)
+		}
+	}
+}
+
+// --- Extra primitive field tests ---
+
+type nameStringer struct{ name string }
+
+func (p *nameStringer) String() string { return p.name }
+
+// --- Error field tests ---
+
+// queryError implements LogFielder.
+type queryError struct{ table string }
+
+func (e *queryError) Error() string      { return "query failed" }
+func (e *queryError) LogFields() []Field { return []Field{String("table", e.table), Int("rows", 0)} }
+
+// pkgStackError mimics github.com/pkg/errors: StackTrace returns a named
+// slice of program counters.
+type (
+	pkgFrame      uintptr
+	pkgStackTrace []pkgFrame
+	pkgStackError struct{ pcs []uintptr }
+)
+
+func (e *pkgStackError) Error() string { return "boom" }
+func (e *pkgStackError) StackTrace() pkgStackTrace {
+	st := make(pkgStackTrace, len(e.pcs))
+	for i, pc := range e.pcs {
+		st[i] = pkgFrame(pc)
+	}
+	return st
+}
+
+func newPkgStackError() error {
+	pcs := make([]uintptr, 8)
+	return &pkgStackError{pcs: pcs[:runtime.Callers(1, pcs)]}
+}
+
+// framesError reports a fixed stack through Frames.
+type framesError struct{}
+
+func (framesError) Error() string { return "frames" }
+func (framesError) Frames() []runtime.Frame {
+	return []runtime.Frame{{Function: "app.run", File: "/src/app/run.go", Line: 12}}
+}
+
+// decodeErrorField logs err with a JSON handler and returns the decoded
+// "error" value.
+func decodeErrorField(t *testing.T, err error) interface{} {
+	t.Helper()
+	w := &testWriter{}
+	newTestLogger(w, NewJSONHandler(w)).Error("failed", "error", err)
+	var rec map[string]interface{}
+	if err := json.Unmarshal([]byte(w.String()), &rec); err != nil {
+		t.Fatalf("invalid JSON %s: %v", w.String(), err)
+	}
+	return rec["error"]
+}
+
+func TestErrorFields(t *testing.T) {
+	if got := decodeErrorField(t, errors.New("plain")); got != "plain" {
+		t.Errorf("plain error = %v", got)
+	}
+
+	// Wrapping and joining alone add nothing worth an object.
+	_, openErr := os.Open(filepath.Join(t.TempDir(), "missing"))
+	wrapped := fmt.Errorf("load config: %w", openErr)
+	if got := decodeErrorField(t, wrapped); got != wrapped.Error() {
+		t.Errorf("wrapped error = %v", got)
+	}
+	if joined := errors.Join(wrapped, errors.New("plain")); decodeErrorField(t, joined) != joined.Error() {
+		t.Errorf("joined error = %v", decodeErrorField(t, joined))
+	}
+	logger := newTestLogger(nil, NewJSONHandler(discardWriteSyncer{}))
+	if n := testing.AllocsPerRun(100, func() { logger.Error("failed", "error", wrapped) }); n != 0 {
+		t.Errorf("logging a wrapped error allocated %v times", n)
+	}
+
+	wrapped = fmt.Errorf("load config: %w", &queryError{table: "config"})
+	got := decodeErrorField(t, wrapped).(map[string]interface{})
+	if got["msg"] != wrapped.Error() || got["type"] != "*fmt.wrapError" {
+		t.Errorf("wrapped error = %v", got)
+	}
+	causes := got["causes"].([]interface{})
+	if len(causes) != 1 || causes[0].(map[string]interface{})["table"] != "config" {
+		t.Errorf("causes = %v", causes)
+	}
+
+	joined := fmt.Errorf("sync: %w", errors.Join(&queryError{table: "users"}, fmt.Errorf("retry: %w", framesError{})))
+	got = decodeErrorField(t, joined).(map[string]interface{})
+	join := got["causes"].([]interface{})[0].(map[string]interface{})
+	if join["type"] != "*errors.joinError" {
+		t.Fatalf("join = %v", join)
+	}
+	members := join["causes"].([]interface{})
+	if len(members) != 2 {
+		t.Fatalf("members = %v", members)
+	}
+	if m := members[0].(map[string]interface{}); m["table"] != "users" || m["rows"] != 0.0 || m["type"] != "*loghq.queryError" {
+		t.Errorf("LogFields member = %v", m)
+	}
+	m := members[1].(map[string]interface{})
+	if stack := m["stack"].([]interface{}); len(stack) != 1 || stack[0] != "app.run /src/app/run.go:12" {
+		t.Errorf("Frames stack = %v", m["stack"])
+	}
+	if _, ok := got["stack"]; ok {
+		t.Errorf("stack hoisted out of join member: %v", got)
+	}
+}
+
+func TestErrorStackTrace(t *testing.T) {
+	err := fmt.Errorf("handler: %w", newPkgStackError())
+	got := decodeErrorField(t, err).(map[string]interface{})
+	stack, _ := got["stack"].([]interface{})
+	if len(stack) == 0 || !strings.HasPrefix(stack[0].(string), "github.com/Bhavyyadav25/loghq.newPkgStackError ") ||
+		!strings.Contains(stack[1].(string), "TestErrorStackTrace") {
+		t.Errorf("stack = %v", stack)
+	}
+	if cause := got["causes"].([]interface{})[0].(map[string]interface{}); cause["type"] != "*loghq.pkgStackError" || cause["stack"] != nil {
+		t.Errorf("cause = %v", cause)
+	}
+}
+
+func TestErrorFieldsTextEncoders(t *testing.T) {
+	err := fmt.Errorf("save: %w", &queryError{table: "users"})
+
+	w := &testWriter{}
+	logger := newTestLogger(w, NewLogfmtHandler(w))
+	logger.Error("failed", "error", err, "other", errors.New("plain"))
+	logger.WithGroup("db").Error("grouped", "error", err)
+	for _, want := range []string{
+		`error.msg="save: query failed" error.type=*fmt.wrapError error.causes=[{msg="query failed",type=*loghq.queryError,table=users,rows=0}] other=plain`,
+		`db.error.msg="save: query failed" db.error.type=*fmt.wrapError`,
+	} {
+		if !strings.Contains(w.String(), want) {
+			t.Errorf("logfmt missing %s in:\n%s", want, w.String())
+		}
+	}
+
+	w.Reset()
+	newTestLogger(w, NewConsoleHandler(WithConsoleWriter(w), WithConsoleNoColor())).Error("failed", "error", err)
+	if !strings.Contains(w.String(), "error.msg=save: query failed error.type=*fmt.wrapError error.causes=[{msg=query failed,") {
+		t.Errorf("console: %s", w.String())
+	}
+
+	rec := acquireRecord()
+	defer releaseRecord(rec)
+	rec.Level, rec.Message = ErrorLevel, "failed"
+	rec.AddField(Err(err))
+	buf := getBuffer()
+	defer putBuffer(buf)
+	(&SyslogEncoder{}).Encode(buf, rec)
+	if got := string(buf.Bytes()); !strings.Contains(got, `error="{\"msg\":\"save: query failed\",\"type\":\"*fmt.wrapError\",`) {
+		t.Errorf("syslog: %s", got)
+	}
+}
+
+// slogFuncHandler passes each slog record to a function.
+type slogFuncHandler func(r slog.Record)
+
+func (h slogFuncHandler) Enabled(context.Context, slog.Level) bool { return true }
+func (h slogFuncHandler) Handle(_ context.Context, r slog.Record) error {
+	h(r)
+	return nil
+}
+func (h slogFuncHandler) WithAttrs([]slog.Attr) slog.Handler { return h }
+func (h slogFuncHandler) WithGroup(string) slog.Handler      { return h }
+
+func TestErrorFieldsRedactedAndSlog(t *testing.T) {
+	err := fmt.Errorf("login ali@example.com: %w", errors.New("denied for ali@example.com"))
+
+	w := &testWriter{}
+	h := NewRedactingHandler(NewJSONHandler(w), RedactPattern(RedactFull, regexp.MustCompile(`[\w.]+@[\w.]+`)))
+	newTestLogger(w, h).Error("failed", "error", err)
+	if out := w.String(); !strings.Contains(out, `"error":"login [REDACTED]: denied for [REDACTED]"`) || strings.Contains(out, "example.com") {
+		t.Errorf("redacted error: %s", out)
+	}
+
+	var got error
+	sh := slogFuncHandler(func(r slog.Record) {
+		r.Attrs(func(a slog.Attr) bool {
+			got, _ = a.Value.Any().(error)
+			return true
+		})
+	})
+	newTestLogger(nil, NewSlogHandler(sh)).Error("failed", "error", err)
+	if got != err {
+		t.Errorf("slog attr = %v, want the logged error", got)
+	}
+}
+
+func TestExtraFieldTypes(t *testing.T) {
+	var nilStringer *nameStringer
+	fields := []Field{
+		Uint64("hash", math.MaxUint64),
+		Bytes("raw", []byte{0xde, 0xad, 0xbe, 0xef}),
+		Complex128("z", complex(1.5, -2)),
+		Stringer("who", &nameStringer{name: "ali"}),
+		Stringer("nobody", nilStringer),
+		Strings("tags", []string{"a", "b"}),
+		Ints("ids", []int{1, 2}),
+		Float64s("ratios", []float64{0.5, 1}),
+		Durations("waits", []time.Duration{time.Second, 2 * time.Millisecond}),
+	}
+
+	tests := []struct {
+		name string
+		h    func(w WriteSyncer) Handler
+		want []string
+	}{
+		{"json", func(w WriteSyncer) Handler { return NewJSONHandler(w) }, []string{
+			`"hash":18446744073709551615`, `"raw":"3q2+7w=="`, `"z":"1.5-2i"`,
+			`"who":"ali"`, `"nobody":"<nil>"`, `"tags":["a","b"]`, `"ids":[1,2]`,
+			`"ratios":[0.5,1]`, `"waits":["1s","2ms"]`,
+		}},
+		{"json hex", func(w WriteSyncer) Handler { return NewJSONHandler(w, WithJSONHexBytes()) }, []string{
+			`"raw":"deadbeef"`,
+		}},
+		{"logfmt", func(w WriteSyncer) Handler { return NewLogfmtHandler(w) }, []string{
+			`hash=18446744073709551615`, `raw=deadbeef`, `z=1.5-2i`, `who=ali`,
+			`nobody=<nil>`, `tags=[a,b]`, `ids=[1,2]`, `ratios=[0.5,1]`, `waits=[1s,2ms]`,
+		}},
+		{"console", func(w WriteSyncer) Handler {
+			return NewConsoleHandler(WithConsoleWriter(w), WithConsoleNoColor())
+		}, []string{
+			`hash=18446744073709551615`, `raw=deadbeef`, `z=1.5-2i`, `who=ali`,
+			`nobody=<nil>`, `tags=[a,b]`, `waits=[1s,2ms]`,
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &testWriter{}
+			newTestLogger(w, tt.h(w)).With(fields...).Info("types")
+			for _, want := range tt.want {
+				if !strings.Contains(w.String(), want) {
+					t.Errorf("missing %s in: %s", want, w.String())
+				}
+			}
+		})
+	}
+}
+
+func TestToFieldUnsigned(t *testing.T) {
+	f := toField("n", uint64(math.MaxUint64))
+	if f.Type != FieldUint64 || uint64(f.Ival) != math.MaxUint64 {
+		t.Errorf("uint64 field: %+v", f)
+	}
+}
+
+// --- Lazy field tests ---
+
+func TestLazyFields(t *testing.T) {
+	w := &testWriter{}
+	logger := newTestLogger(w, NewJSONHandler(w))
+	logger.SetLevel(InfoLevel)
+
+	calls := 0
+	expensive := func() interface{} { calls++; return []string{"a", "b"} }
+	diff := func() Field { calls++; return Int("changed", 3) }
+
+	logger.Debug("skipped", Lazy("payload", expensive), LazyField(diff))
+	logger.With(Lazy("payload", expensive)).Debug("skipped")
+	if calls != 0 {
+		t.Fatalf("lazy fields evaluated for disabled level: %d calls", calls)
+	}
+
+	logger.With(Lazy("payload", expensive)).Info("logged", "diff", LazyField(diff))
+	if calls != 2 {
+		t.Errorf("expected 2 evaluations, got %d", calls)
+	}
+	out := w.String()
+	if !strings.Contains(out, `"payload":["a","b"]`) || !strings.Contains(out, `"changed":3`) {
+		t.Errorf("lazy values missing: %s", out)
+	}
+}
+
+func TestLazyFieldsInGroups(t *testing.T) {
+	calls := 0
+	k := func() interface{} { calls++; return 7 }
+	inner := func() Field { return Group("inner", Lazy("n", k)) }
+
+	tests := []struct {
+		name string
+		h    func(w WriteSyncer) Handler
+		want []string
+	}{
+		{"json", func(w WriteSyncer) Handler { return NewJSONHandler(w) },
+			[]string{`"g":{"k":7,"s":"v","inner":{"n":7}}`, `"o":{"g":{"k":7}}`}},
+		{"logfmt", func(w WriteSyncer) Handler { return NewLogfmtHandler(w) },
+			[]string{"g.k=7 g.s=v g.inner.n=7", "o.g.k=7"}},
+		{"console", func(w WriteSyncer) Handler {
+			return NewConsoleHandler(WithConsoleWriter(w), WithConsoleNoColor())
+		}, []string{"g.k=7", "g.inner.n=7", "o.g.k=7"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &testWriter{}
+			logger := newTestLogger(w, tt.h(w))
+			g := Group("g", Lazy("k", k), String("s", "v"), LazyField(inner))
+			logger.With(g).Info("bound")
+			logger.Info("obj", "o", Object("o", ObjectMarshalerFunc(func(enc FieldEncoder) error {
+				enc.EncodeGroup("g", []Field{Lazy("k", k)})
+				return nil
+			})))
+			for _, want := range tt.want {
+				if !strings.Contains(w.String(), want) {
+					t.Errorf("missing %s in: %s", want, w.String())
+				}
+			}
+		})
+	}
+	if calls == 0 {
+		t.Error("lazy group members never evaluated")
+	}
+
+	w := &testWriter{}
+	logger := newTestLogger(w, NewJSONHandler(w))
+	g := Group("g", Lazy("k", k))
+	logger.With(g).Info("again")
+	if fields := g.Iface.([]Field); fields[0].Type != FieldLazy {
+		t.Error("resolving a bound group modified it")
+	}
+	if err := json.Unmarshal([]byte(w.String()), new(map[string]interface{})); err != nil {
+		t.Errorf("invalid JSON: %v\n%s", err, w.String())
+	}
+}
+
+func TestLoggerEnabled(t *testing.T) {
+	h := NewJSONHandler(&testWriter{}, WithJSONLevel(WarnLevel))
+	logger := New(WithHandler(h), WithLevel(DebugLevel))
+
+	if logger.Enabled(TraceLevel) {
+		t.Error("TraceLevel is below the logger level")
+	}
+	if logger.Enabled(InfoLevel) {
+		t.Error("InfoLevel is below the handler level")
+	}
+	if !logger.Enabled(ErrorLevel) {
+		t.Error("ErrorLevel should be enabled")
+	}
+}
+
+func TestLoggerSkipsRecordsHandlerDisables(t *testing.T) {
+	w := &testWriter{}
+	logger := New(WithHandler(NewJSONHandler(w, WithJSONLevel(WarnLevel))), WithLevel(TraceLevel))
+
+	calls := 0
+	lazy := Lazy("n", func() interface{} { calls++; return calls })
+	logger.With(lazy).Info("below handler level")
+	if calls != 0 || w.String() != "" {
+		t.Errorf("record built for a disabled handler: %d calls, output %q", calls, w.String())
+	}
+	logger.With(lazy).Warn("written")
+	if calls != 1 || !strings.Contains(w.String(), `"n":1`) {
+		t.Errorf("calls = %d, output %s", calls, w.String())
+	}
+}
+
+// --- Config tests ---
+
+func TestConfigJSON(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	data := `{
+		"level": "debug",
+		"caller": false,
+		"stack_level": "fatal",
+		"handlers": [
+			{"format": "json", "output": "file", "level": "warn",
+			 "file": {"path": ` + strconv.Quote(path) + `, "max_size": 1048576, "max_age": "24h"}}
+		]
+	}`
+	cfg, err := ParseJSONConfig([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Level != DebugLevel || *cfg.StackLevel != FatalLevel || *cfg.Handlers[0].Level != WarnLevel {
+		t.Errorf("levels not decoded: %+v", cfg)
+	}
+	if cfg.Handlers[0].File.MaxAge != 24*time.Hour {
+		t.Errorf("max_age = %v", cfg.Handlers[0].File.MaxAge)
+	}
+
+	logger, err := cfg.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if logger.addCaller || logger.stackLevel != FatalLevel || logger.LevelVar().Level() != DebugLevel {
+		t.Errorf("logger options not applied")
+	}
+	logger.Info("dropped by handler level")
+	logger.Error("written", "k", 1)
+	if err := logger.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(out), "dropped") || !strings.Contains(string(out), `"msg":"written","k":1`) {
+		t.Errorf("unexpected file contents: %s", out)
+	}
+}
+
+func TestConfigKeyValue(t *testing.T) {
+	cfg, err := ParseConfig([]byte(`
+# service logging
+level = warn
+format = logfmt
+output = stdout
+file.max_backups = 3
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Level != WarnLevel || len(cfg.Handlers) != 1 {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+	hc := cfg.Handlers[0]
+	if hc.Format != "logfmt" || hc.Output != "stdout" || hc.File.MaxBackups != 3 {
+		t.Errorf("unexpected handler config: %+v", hc)
+	}
+
+	if _, err := ParseConfig([]byte("level=debug\nbogus=1\n")); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("expected line-numbered error, got %v", err)
+	}
+	if _, err := ParseConfig([]byte("level=loud")); err == nil {
+		t.Error("expected invalid level error")
+	}
+	if _, err := ParseJSONConfig([]byte(`{"levle":"info"}`)); err == nil {
+		t.Error("expected unknown JSON key error")
+	}
+	if _, err := ParseJSONConfig([]byte(`{"handlers":[{"output":"file","file":{"path":"a.log","max_sizes":1}}]}`)); err == nil {
+		t.Error("expected unknown file key error")
+	}
+}
+
+func TestConfigEnvOverride(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "loghq.conf")
+	if err := os.WriteFile(path, []byte("level=info\nformat=console\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("LOGHQ_LEVEL", "trace")
+	t.Setenv("LOGHQ_FORMAT", "json")
+
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Level != TraceLevel || cfg.Handlers[0].Format != "json" {
+		t.Errorf("env overrides not applied: %+v", cfg)
+	}
+
+	t.Setenv("LOGHQ_LEVEL", "nope")
+	if _, err := LoadConfig(path); err == nil || !strings.Contains(err.Error(), "LOGHQ_LEVEL") {
+		t.Errorf("expected LOGHQ_LEVEL error, got %v", err)
+	}
+}
+
+func TestConfigBuildErrors(t *testing.T) {
+	for _, cfg := range []Config{
+		{Handlers: []HandlerConfig{{Format: "xml"}}},
+		{Handlers: []HandlerConfig{{Output: "printer"}}},
+	} {
+		if _, err := cfg.Build(); err == nil {
+			t.Errorf("expected error for %+v", cfg.Handlers[0])
+		}
+	}
+}
+
+func TestLevelText(t *testing.T) {
+	for _, lvl := range []Level{TraceLevel, DebugLevel, InfoLevel, SuccessLevel, WarnLevel, ErrorLevel, FatalLevel} {
+		text, err := lvl.MarshalText()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got Level
+		if err := got.UnmarshalText(text); err != nil || got != lvl {
+			t.Errorf("%s: round trip gave %v, %v", text, got, err)
+		}
+	}
+}
+
+// --- LevelVar tests ---
+
+func TestLevelVarShared(t *testing.T) {
+	lv := NewLevelVar(WarnLevel)
+	w := &testWriter{}
+	h := NewJSONHandler(w, WithJSONLevelVar(lv))
+	a := New(WithHandler(h), WithLevelVar(lv))
+	b := New(WithHandler(h), WithLevelVar(lv)).With(String("svc", "b"))
+
+	a.Info("hidden")
+	b.Info("hidden")
+	lv.Set(DebugLevel)
+	a.Debug("shown-a")
+	b.Debug("shown-b")
+
+	out := w.String()
+	if strings.Contains(out, "hidden") || !strings.Contains(out, "shown-a") || !strings.Contains(out, "shown-b") {
+		t.Errorf("shared level not honored: %s", out)
+	}
+	if h.LevelVar() != lv || a.LevelVar() != lv {
+		t.Error("LevelVar accessors should return the shared variable")
+	}
+}
+
+func TestDerivedLoggerLevelIsCopied(t *testing.T) {
+	parent := New(WithLevel(InfoLevel))
+	child := parent.With(String("k", "v"))
+	sibling := parent.WithGroup("g")
+
+	child.SetLevel(ErrorLevel)
+	if parent.LevelVar().Level() != InfoLevel || sibling.LevelVar().Level() != InfoLevel {
+		t.Error("SetLevel on a derived logger changed its parent or sibling")
+	}
+	parent.SetLevel(DebugLevel)
+	if child.LevelVar().Level() != ErrorLevel {
+		t.Error("SetLevel on the parent changed a derived logger")
+	}
+
+	// WithLevelVar opts back into sharing, including through WithOptions.
+	lv := parent.LevelVar()
+	shared := parent.WithOptions(WithLevelVar(lv)).With(String("k", "v"))
+	lv.Set(WarnLevel)
+	if shared.LevelVar() != lv || shared.Enabled(InfoLevel) {
+		t.Error("logger built with WithLevelVar does not share it")
+	}
+}
+
+func TestLevelVarSetFor(t *testing.T) {
+	lv := NewLevelVar(InfoLevel)
+	lv.SetFor(DebugLevel, 20*time.Millisecond)
+	lv.SetFor(TraceLevel, 20*time.Millisecond)
+	if lv.Level() != TraceLevel {
+		t.Fatalf("level = %v, want TRACE", lv.Level())
+	}
+	if _, ok := lv.Expires(); !ok {
+		t.Fatal("expected a pending revert")
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for lv.Level() != InfoLevel && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if lv.Level() != InfoLevel {
+		t.Fatalf("level = %v, want revert to INFO", lv.Level())
+	}
+
+	lv.SetFor(DebugLevel, 20*time.Millisecond)
+	lv.Set(ErrorLevel)
+	time.Sleep(50 * time.Millisecond)
+	if lv.Level() != ErrorLevel {
+		t.Errorf("Set should cancel the pending revert, got %v", lv.Level())
+	}
+}
+
+func TestLevelVarHTTP(t *testing.T) {
+	lv := NewLevelVar(InfoLevel)
+	srv := httptest.NewServer(lv)
+	defer srv.Close()
+
+	do := func(method, query, contentType, body string) (int, string) {
+		req, err := http.NewRequest(method, srv.URL+query, strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		b, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(b)
+	}
+
+	if code, body := do("GET", "", "", ""); code != 200 || strings.TrimSpace(body) != `{"level":"info"}` {
+		t.Errorf("GET: %d %s", code, body)
+	}
+	if code, body := do("PUT", "", "text/plain", "debug"); code != 200 || body != "debug\n" {
+		t.Errorf("PUT text: %d %q", code, body)
+	}
+	if lv.Level() != DebugLevel {
+		t.Errorf("level = %v after PUT", lv.Level())
+	}
+
+	code, body := do("PUT", "", "application/json", `{"level":"trace","ttl":"1h"}`)
+	if code != 200 || !strings.Contains(body, `"level":"trace"`) || !strings.Contains(body, `"revert_to":"debug"`) {
+		t.Errorf("PUT json: %d %s", code, body)
+	}
+	lv.Set(InfoLevel)
+
+	if code, _ := do("PUT", "", "text/plain", "verbose"); code != http.StatusBadRequest {
+		t.Errorf("invalid level: status %d", code)
+	}
+	if code, _ := do("PUT", "?ttl=soon", "text/plain", "warn"); code != http.StatusBadRequest {
+		t.Errorf("invalid ttl: status %d", code)
+	}
+	if code, body := do("POST", "", "application/json", `{}`); code != http.StatusMethodNotAllowed || !strings.Contains(body, `"error"`) {
+		t.Errorf("POST: %d %s", code, body)
+	}
+	if lv.Level() != InfoLevel {
+		t.Errorf("rejected requests changed the level to %v", lv.Level())
+	}
+}
+
+// --- Named logger tests ---
+
+func TestNamedLoggerLevels(t *testing.T) {
+	w := &testWriter{}
+	root := newTestLogger(w, NewJSONHandler(w))
+	root.SetLevel(InfoLevel)
+	if err := root.LevelRules().Parse("payments=debug, cache=warn, payments.ledger=error"); err != nil {
+		t.Fatal(err)
+	}
+
+	payments := root.Named("payments")
+	ledger := payments.Named("ledger")
+	refunds := payments.Named("refunds").With(String("k", "v"))
+	cache := root.Named("cache")
+	paymentsx := root.Named("paymentsx")
+
+	if ledger.Name() != "payments.ledger" {
+		t.Errorf("name = %q", ledger.Name())
+	}
+
+	payments.Debug("payments-debug")
+	refunds.Debug("refunds-debug")
+	ledger.Warn("ledger-warn")
+	ledger.Error("ledger-error")
+	cache.Info("cache-info")
+	cache.Warn("cache-warn")
+	paymentsx.Debug("paymentsx-debug")
+	root.Debug("root-debug")
+
+	out := w.String()
+	for _, want := range []string{"payments-debug", "refunds-debug", "ledger-error", "cache-warn"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %s in: %s", want, out)
+		}
+	}
+	for _, reject := range []string{"ledger-warn", "cache-info", "paymentsx-debug", "root-debug"} {
+		if strings.Contains(out, reject) {
+			t.Errorf("unexpected %s in: %s", reject, out)
+		}
+	}
+
+	// Runtime changes are picked up by existing loggers.
+	root.LevelRules().Set("cache", DebugLevel)
+	root.LevelRules().Delete("payments.ledger")
+	if !cache.Enabled(DebugLevel) || !ledger.Enabled(DebugLevel) {
+		t.Errorf("rule changes not applied: %s", root.LevelRules())
+	}
+	if got := root.LevelRules().String(); got != "cache=debug,payments=debug" {
+		t.Errorf("String() = %q", got)
+	}
+}
+
+func TestNamedLoggerEncoders(t *testing.T) {
+	tests := []struct {
+		name string
+		h    func(w WriteSyncer) Handler
+		want string
+	}{
+		{"json", func(w WriteSyncer) Handler { return NewJSONHandler(w) }, `"level":"INFO","logger":"api.auth","msg":"hi"`},
+		{"logfmt", func(w WriteSyncer) Handler { return NewLogfmtHandler(w) }, `level=info logger=api.auth msg=hi`},
+		{"console", func(w WriteSyncer) Handler {
+			return NewConsoleHandler(WithConsoleWriter(w), WithConsoleNoColor())
+		}, `[api.auth] hi`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &testWriter{}
+			newTestLogger(w, tt.h(w)).Named("api").Named("auth").Info("hi")
+			if !strings.Contains(w.String(), tt.want) {
+				t.Errorf("missing %s in: %s", tt.want, w.String())
+			}
+		})
+	}
+}
+
+func TestParseLevelRules(t *testing.T) {
+	rules, err := ParseLevelRules("payments=debug,cache=warn")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rules["payments"] != DebugLevel || rules["cache"] != WarnLevel {
+		t.Errorf("unexpected rules: %v", rules)
+	}
+	for _, bad := range []string{"payments", "=debug", "cache=loud"} {
+		if _, err := ParseLevelRules(bad); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+
+	t.Setenv("LOGHQ_LEVELS", "db=trace")
+	cfg := &Config{}
+	if err := cfg.ApplyEnv(); err != nil {
+		t.Fatal(err)
+	}
+	logger, err := cfg.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !logger.Named("db").Enabled(TraceLevel) || logger.Enabled(DebugLevel) {
+		t.Error("LOGHQ_LEVELS not applied")
+	}
+}
+
+func TestNilLevelRules(t *testing.T) {
+	w := &testWriter{}
+	logger := New(WithHandler(NewLogfmtHandler(w)), WithLevelRules(nil)).Named("db")
+	logger.Info("ok")
+	if logger.LevelRules() == nil || !strings.Contains(w.String(), "msg=ok") {
+		t.Errorf("nil rules: %v, output %q", logger.LevelRules(), w.String())
+	}
+}
+
+// --- Router tests ---
+
+func TestRouterHandler(t *testing.T) {
+	audit, errs, rest := &testWriter{}, &testWriter{}, &testWriter{}
+	router := NewRouterHandler(
+		WithRoute(NewLogfmtHandler(audit), MatchFieldValue("audit", true)),
+		WithRoute(NewLogfmtHandler(errs), MatchMinLevel(ErrorLevel)),
+		WithRouteFallback(NewLogfmtHandler(rest)),
+	)
+	logger := newTestLogger(nil, router)
+
+	logger.Info("login", "audit", true)
+	logger.Error("audit failure", "audit", true)
+	logger.Error("boom")
+	logger.Info("hello", "audit", false)
+
+	if got := audit.String(); !strings.Contains(got, "msg=login") || !strings.Contains(got, "audit failure") {
+		t.Errorf("audit route: %s", got)
+	}
+	if got := errs.String(); !strings.Contains(got, "msg=boom") || strings.Contains(got, "audit failure") {
+		t.Errorf("error route (first match): %s", got)
+	}
+	if got := rest.String(); !strings.Contains(got, "msg=hello") || strings.Contains(got, "boom") {
+		t.Errorf("fallback: %s", got)
+	}
+}
+
+func TestRouterHandlerAllMatch(t *testing.T) {
+	file, stderr, other := &testWriter{}, &testWriter{}, &testWriter{}
+	router := NewRouterHandler(
+		WithRouteAll(),
+		WithRoute(NewLogfmtHandler(file), MatchLevels(WarnLevel, FatalLevel)),
+		WithRoute(NewLogfmtHandler(stderr), MatchMinLevel(ErrorLevel), MatchNot(MatchMessagePrefix("quiet"))),
+		WithRoute(NewLogfmtHandler(other), MatchAny(MatchField("user"), MatchMessagePrefix("http "))),
+	)
+	logger := newTestLogger(nil, router)
+
+	logger.Error("disk full")
+	logger.Error("quiet retry")
+	logger.Warn("http slow", "user", "ali")
+	logger.Info("idle")
+
+	if got := file.String(); strings.Count(got, "\n") != 3 {
+		t.Errorf("file route: %s", got)
+	}
+	if got := stderr.String(); !strings.Contains(got, "disk full") || strings.Contains(got, "quiet") {
+		t.Errorf("stderr route: %s", got)
+	}
+	if got := other.String(); !strings.Contains(got, "http slow") || strings.Contains(got, "idle") {
+		t.Errorf("field route: %s", got)
+	}
+}
+
+// sliceHandler is a handler whose dynamic type is not comparable.
+type sliceHandler struct {
+	Handler
+	closed *int
+	tags   []string
+}
+
+func (h sliceHandler) Close() error { *h.closed++; return nil }
+
+func TestRouterHandlerUncomparableHandlers(t *testing.T) {
+	closed := 0
+	h := sliceHandler{Handler: discardHandler{}, closed: &closed, tags: []string{"a"}}
+	shared := closeRecorder{Handler: discardHandler{}, calls: new([]string)}
+	router := NewRouterHandler(
+		WithRoute(h, MatchMinLevel(ErrorLevel)),
+		WithRoute(shared, MatchMinLevel(WarnLevel)),
+		WithRoute(shared, MatchMinLevel(InfoLevel)),
+		WithRouteFallback(h),
+	)
+	if err := router.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if closed != 2 || len(*shared.calls) != 1 {
+		t.Errorf("closed %d uncomparable and %d shared handlers", closed, len(*shared.calls))
+	}
+}
+
+func TestRouterMatchers(t *testing.T) {
+	rec := acquireRecord()
+	defer releaseRecord(rec)
+	rec.Caller = newCallerInfo("/src/app/payments/ledger.go", 10, "github.com/acme/app/payments/ledger.(*Book).Post")
+	rec.AddField(Int("status", 500))
+	rec.AddField(String("path", "/healthz"))
+
+	tests := []struct {
+		name string
+		m    RouteMatcher
+		want bool
+	}{
+		{"int value", MatchFieldValue("status", 500), true},
+		{"int as text", MatchFieldValue("status", "500"), true},
+		{"int mismatch", MatchFieldValue("status", 404), false},
+		{"string value", MatchFieldValue("path", "/healthz"), true},
+		{"missing field", MatchFieldValue("user", "x"), false},
+		{"package prefix", MatchCallerPackage("github.com/acme/app/payments"), true},
+		{"exact package", MatchCallerPackage("github.com/acme/app/payments/ledger"), true},
+		{"partial segment", MatchCallerPackage("github.com/acme/app/pay"), false},
+	}
+	for _, tt := range tests {
+		if got := tt.m(rec); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// --- Filter tests ---
+
+func TestFilterExpressions(t *testing.T) {
+	rec := acquireRecord()
+	defer releaseRecord(rec)
+	rec.Level = InfoLevel
+	rec.Message = "GET /healthz"
+	rec.Name = "http.server"
+	rec.Caller = newCallerInfo("/src/app/http/server.go", 42, "app/http.serve")
+	rec.AddField(String("path", "/healthz"))
+	rec.AddField(Int("status", 200))
+	rec.AddField(Duration("latency", 300*time.Millisecond))
+	rec.AddField(Bool("cached", true))
+	rec.AddField(Float64("ratio", 0.5))
+
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{`level>=warn || path!="/healthz"`, false},
+		{`level>=info && path=="/healthz"`, true},
+		{`level==INFO`, true},
+		{`level<debug`, false},
+		{`status>=500`, false},
+		{`status==200 && latency>250ms`, true},
+		{`status=="200"`, true},
+		{`ratio<1`, true},
+		{`cached==true`, true},
+		{`cached`, true},
+		{`user`, false},
+		{`!user && !(status!=200)`, true},
+		{`user=="ali"`, false},
+		{`user!="ali"`, true},
+		{`msg=~"^GET "`, true},
+		{`msg!~"health"`, false},
+		{`caller=="http/server.go"`, true},
+		{`logger=="http.server"`, true},
+		{`path==/healthz`, true},
+		{`true && (false || status<300)`, true},
+	}
+	for _, tt := range tests {
+		f, err := CompileFilter(tt.expr)
+		if err != nil {
+			t.Errorf("%s: %v", tt.expr, err)
+			continue
+		}
+		if got := f.Match(rec); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+
+	f := MustCompileFilter(`level>=warn || path!="/healthz" && status<500 && latency>1s && msg=~"GET"`)
+	if allocs := testing.AllocsPerRun(100, func() { f.Match(rec) }); allocs != 0 {
+		t.Errorf("Match allocated %v times", allocs)
+	}
+}
+
+func TestFilterCompileErrors(t *testing.T) {
+	for _, expr := range []string{
+		``,
+		`level>=`,
+		`level>=loud`,
+		`level=~"x"`,
+		`(status==1`,
+		`status==1)`,
+		`path=="unterminated`,
+		`msg=~"("`,
+		`a && || b`,
+		`status # 1`,
+		`(level>=warn) #junk`,
+		`(a) @`,
+		`a && b "open`,
+	} {
+		if _, err := CompileFilter(expr); err == nil {
+			t.Errorf("%q: expected error", expr)
+		}
+	}
+}
+
+func TestFilterHandler(t *testing.T) {
+	w := &testWriter{}
+	fh, err := NewFilterHandler(NewLogfmtHandler(w), `level>=warn || path!="/healthz"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := newTestLogger(nil, fh)
+
+	logger.Info("probe", "path", "/healthz")
+	logger.Warn("slow probe", "path", "/healthz")
+	logger.Info("request", "path", "/orders")
+
+	if err := fh.SetExpr(`level>=`); err == nil {
+		t.Error("expected compile error")
+	}
+	if err := fh.SetExpr(`path!="/orders"`); err != nil {
+		t.Fatal(err)
+	}
+	logger.Info("request again", "path", "/orders")
+	fh.SetFilter(nil)
+	logger.Info("unfiltered", "path", "/orders")
+
+	out := w.String()
+	for _, want := range []string{"slow probe", "msg=request ", "unfiltered"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in: %s", want, out)
+		}
+	}
+	for _, reject := range []string{"msg=probe", "request again"} {
+		if strings.Contains(out, reject) {
+			t.Errorf("unexpected %q in: %s", reject, out)
+		}
+	}
+}
+
+// --- NetWriter tests ---
+
+// readLines collects newline-terminated lines from every connection
+// accepted on ln.
+func readLines(ln net.Listener) <-chan string {
+	lines := make(chan string, 100)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				buf := make([]byte, 0, 4096)
+				tmp := make([]byte, 1024)
+				for {
+					n, err := conn.Read(tmp)
+					buf = append(buf, tmp[:n]...)
+					for {
+						i := bytes.IndexByte(buf, '\n')
+						if i < 0 {
+							break
+						}
+						lines <- string(buf[:i])
+						buf = buf[i+1:]
+					}
+					if err != nil {
+						return
+					}
+				}
+			}()
+		}
+	}()
+	return lines
+}
+
+func waitLine(t *testing.T, lines <-chan string, want string) {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case line := <-lines:
+			if strings.Contains(line, want) {
+				return
+			}
+		case <-timeout:
+			t.Fatalf("timed out waiting for %q", want)
+		}
+	}
+}
+
+func TestNetWriterTCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	lines := readLines(ln)
+
+	w, err := NewNetWriter(NetConfig{Network: "tcp", Address: ln.Addr().String()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	logger := newTestLogger(nil, NewLogfmtHandler(w))
+	logger.Info("over tcp", "n", 1)
+	waitLine(t, lines, "msg=\"over tcp\" n=1")
+	if err := w.Sync(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestNetWriterReconnect(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close() // collector is down at startup
+
+	w, err := NewNetWriter(NetConfig{
+		Network:    "tcp",
+		Address:    addr,
+		MinBackoff: 10 * time.Millisecond,
+		MaxBackoff: 50 * time.Millisecond,
+		BufferSize: 64,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		fmt.Fprintf(w, "buffered-%d-%s\n", i, strings.Repeat("x", 10))
+	}
+	if time.Since(start) > time.Second {
+		t.Error("writes blocked while disconnected")
+	}
+	if w.Connected() || w.Sync() == nil {
+		t.Error("expected a disconnected writer with buffered data")
+	}
+	if w.Dropped() == 0 {
+		t.Error("expected the oldest messages to be dropped from the full buffer")
+	}
+
+	ln, err = net.Listen("tcp", addr)
+	if err != nil {
+		t.Skipf("cannot rebind %s: %v", addr, err)
+	}
+	defer ln.Close()
+	lines := readLines(ln)
+	waitLine(t, lines, "buffered-4")
+
+	fmt.Fprintln(w, "live")
+	waitLine(t, lines, "live")
+}
+
+// stubConn records what is written to it. It accepts up to limit bytes
+// (all of them if limit < 0) before failing, and blocks writes while
+// stall is open.
+type stubConn struct {
+	net.Conn
+	mu    sync.Mutex
+	buf   bytes.Buffer
+	limit int
+	stall chan struct{}
+}
+
+func (c *stubConn) Write(p []byte) (int, error) {
+	if c.stall != nil {
+		<-c.stall
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.limit >= 0 && len(p) > c.limit {
+		n := c.limit
+		c.buf.Write(p[:n])
+		c.limit = 0
+		return n, errors.New("connection reset")
+	}
+	if c.limit > 0 {
+		c.limit -= len(p)
+	}
+	return c.buf.Write(p)
+}
+
+func (c *stubConn) String() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.buf.String()
+}
+
+func (c *stubConn) SetWriteDeadline(time.Time) error { return nil }
+func (c *stubConn) Close() error                     { return nil }
+
+// newStubNetWriter starts a NetWriter on conn whose reconnects take the
+// next connection from next.
+func newStubNetWriter(conn net.Conn, next <-chan net.Conn) *NetWriter {
+	w := &NetWriter{
+		cfg:  NetConfig{Network: "tcp", MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond},
+		conn: conn,
+		kick: make(chan struct{}, 1),
+		done: make(chan struct{}),
+		dial: func() (net.Conn, error) {
+			select {
+			case c := <-next:
+				return c, nil
+			default:
+				return nil, errors.New("collector down")
+			}
+		},
+	}
+	w.wg.Add(1)
+	go w.reconnectLoop()
+	return w
+}
+
+func TestNetWriterPartialWrite(t *testing.T) {
+	first, second, third := &stubConn{limit: 5}, &stubConn{limit: 15}, &stubConn{limit: -1}
+	next := make(chan net.Conn, 2)
+	w := newStubNetWriter(first, next)
+	defer w.Close()
+
+	fmt.Fprintln(w, "hello world")
+	if w.Connected() {
+		t.Fatal("failed write did not drop the connection")
+	}
+	fmt.Fprintln(w, "second msg")
+	next <- second
+	next <- third
+	for deadline := time.Now().Add(2 * time.Second); !w.Connected(); {
+		if time.Now().After(deadline) {
+			t.Fatal("writer did not reconnect")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	// A message cut short is resent whole on the next connection.
+	if got := second.String(); got != "hello world\nsec" {
+		t.Errorf("second connection got %q", got)
+	}
+	if got := third.String(); got != "second msg\n" {
+		t.Errorf("third connection got %q, want the whole message", got)
+	}
+}
+
+func TestNetWriterStalledWriteDoesNotBlock(t *testing.T) {
+	conn := &stubConn{limit: -1, stall: make(chan struct{})}
+	w := newStubNetWriter(conn, nil)
+
+	go fmt.Fprintln(w, "stalled")
+	time.Sleep(10 * time.Millisecond)
+
+	done := make(chan struct{})
+	go func() {
+		w.Connected()
+		w.Sync()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Error("Connected and Sync blocked behind a stalled write")
+	}
+	close(conn.stall)
+	w.Close()
+}
+
+func TestNetWriterDatagram(t *testing.T) {
+	dir, err := os.MkdirTemp("", "loghq")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	udp, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer udp.Close()
+	unixgram, err := net.ListenPacket("unixgram", filepath.Join(dir, "s"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer unixgram.Close()
+
+	for _, pc := range []net.PacketConn{udp, unixgram} {
+		network := pc.LocalAddr().Network()
+		w, err := NewNetWriter(NetConfig{Network: network, Address: pc.LocalAddr().String()})
+		if err != nil {
+			t.Fatal(err)
+		}
+		newTestLogger(nil, NewJSONHandler(w)).Info("datagram")
+
+		buf := make([]byte, 1024)
+		pc.SetReadDeadline(time.Now().Add(5 * time.Second))
+		n, _, err := pc.ReadFrom(buf)
+		if err != nil {
+			t.Fatalf("%s: %v", network, err)
+		}
+		if !strings.Contains(string(buf[:n]), `"msg":"datagram"`) {
+			t.Errorf("%s: got %s", network, buf[:n])
+		}
+		w.Close()
+		if _, err := w.Write([]byte("x")); err == nil {
+			t.Errorf("%s: write after Close should fail", network)
+		}
+	}
+}
+
+func TestNetWriterConfigErrors(t *testing.T) {
+	for _, cfg := range []NetConfig{
+		{Network: "sctp", Address: "x"},
+		{Network: "tcp"},
+		{Network: "udp", Address: "127.0.0.1:1", TLS: &tls.Config{}},
+	} {
+		if _, err := NewNetWriter(cfg); err == nil {
+			t.Errorf("expected error for %+v", cfg)
+		}
+	}
+}
+
+// --- Syslog tests ---
+
+func TestSyslogEncoderRFC5424(t *testing.T) {
+	rec := acquireRecord()
+	defer releaseRecord(rec)
+	rec.Time = time.Date(2024, 3, 1, 12, 30, 45, 123456789, time.UTC)
+	rec.Level = SuccessLevel
+	rec.Message = "payment settled"
+	rec.Name = "payments"
+	rec.AddField(String("id", `a"b]c\d`))
+	rec.AddField(Int("amount", 42))
+	rec.AddField(Group("user", String("name", "ali")))
+	rec.AddField(Strings("tags", []string{"x", "y"}))
+
+	enc := &SyslogEncoder{Facility: FacilityLocal0, Hostname: "web 1", AppName: "api", ProcID: "77"}
+	buf := getBuffer()
+	defer putBuffer(buf)
+	enc.Encode(buf, rec)
+
+	want := `<133>1 2024-03-01T12:30:45.123456Z web_1 api 77 payments ` +
+		`[fields@32473 id="a\"b\]c\\d" amount="42" user.name="ali" tags="[\"x\",\"y\"\]"] payment settled` + "\n"
+	if got := string(buf.Bytes()); got != want {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+
+	// No fields: NILVALUE structured data, octet-counting framing.
+	rec.nFields = 0
+	rec.Name = ""
+	enc.Framing = SyslogFramingOctetCounting
+	buf.Reset()
+	enc.Encode(buf, rec)
+	msg := `<133>1 2024-03-01T12:30:45.123456Z web_1 api 77 - - payment settled`
+	if got := string(buf.Bytes()); got != strconv.Itoa(len(msg))+" "+msg {
+		t.Errorf("octet counting: %q", got)
+	}
+}
+
+func TestSyslogEncoderRFC3164(t *testing.T) {
+	rec := acquireRecord()
+	defer releaseRecord(rec)
+	rec.Time = time.Date(2024, 3, 1, 9, 5, 3, 0, time.UTC)
+	rec.Level = ErrorLevel
+	rec.Message = "disk full"
+	rec.AddField(String("mount", "/var"))
+
+	enc := &SyslogEncoder{Format: SyslogRFC3164, Framing: SyslogFramingNone, Facility: FacilityDaemon, Hostname: "db", AppName: "agent", ProcID: "9"}
+	buf := getBuffer()
+	defer putBuffer(buf)
+	enc.Encode(buf, rec)
+
+	if got, want := string(buf.Bytes()), `<27>Mar  1 09:05:03 db agent[9]: disk full mount=/var`; got != want {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+}
+
+func TestSyslogEncoderLineBreaks(t *testing.T) {
+	rec := acquireRecord()
+	defer releaseRecord(rec)
+	rec.Time = time.Date(2024, 3, 1, 12, 30, 45, 0, time.UTC)
+	rec.Level = ErrorLevel
+	rec.Message = "boom\r\nagain"
+	rec.Stack = "main.main()\n\t/src/main.go:12\n"
+	rec.AddField(String("sql", "SELECT 1\nFROM t"))
+
+	buf := getBuffer()
+	defer putBuffer(buf)
+	(&SyslogEncoder{Hostname: "h", AppName: "a"}).Encode(buf, rec)
+	want := `<3>1 2024-03-01T12:30:45.000000Z h a - - [fields@32473 sql="SELECT 1\nFROM t" ` +
+		`stack="main.main()\n` + "\t" + `/src/main.go:12\n"] boom\r\nagain` + "\n"
+	if got := string(buf.Bytes()); got != want {
+		t.Errorf("got  %q\nwant %q", got, want)
+	}
+
+	buf.Reset()
+	(&SyslogEncoder{Format: SyslogRFC3164, Hostname: "h", AppName: "a"}).Encode(buf, rec)
+	if got := string(buf.Bytes()); strings.Count(got, "\n") != 1 || !strings.Contains(got, `a: boom\r\nagain sql="SELECT 1\nFROM t"`) {
+		t.Errorf("RFC 3164 record spans lines: %q", got)
+	}
+}
+
+func TestSyslogSeverity(t *testing.T) {
+	want := map[Level]int{TraceLevel: 7, DebugLevel: 7, InfoLevel: 6, SuccessLevel: 5, WarnLevel: 4, ErrorLevel: 3, FatalLevel: 2}
+	for lvl, sev := range want {
+		if got := SyslogSeverity(lvl); got != sev {
+			t.Errorf("%v: got %d, want %d", lvl, got, sev)
+		}
+	}
+}
+
+func TestSyslogHandlerOverTCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	lines := readLines(ln)
+
+	nw, err := NewNetWriter(NetConfig{Network: "tcp", Address: ln.Addr().String()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer nw.Close()
+	h := NewSyslogHandler(nw, WithSyslogHeader("host", "svc", "1"), WithSyslogFacility(FacilityLocal3))
+	newTestLogger(nil, h).Warn("queue backlog", "depth", 900)
+	waitLine(t, lines, `<156>1 `)
+}
+
+// --- Journal tests ---
+
+// parseJournal decodes a native journal protocol payload.
+func parseJournal(t *testing.T, b []byte) map[string]string {
+	t.Helper()
+	fields := make(map[string]string)
+	for len(b) > 0 {
+		nl := bytes.IndexByte(b, '\n')
+		if nl < 0 {
+			t.Fatalf("unterminated entry: %q", b)
+		}
+		line := b[:nl]
+		if eq := bytes.IndexByte(line, '='); eq >= 0 {
+			fields[string(line[:eq])] = string(line[eq+1:])
+			b = b[nl+1:]
+			continue
+		}
+		n := binary.LittleEndian.Uint64(b[nl+1:])
+		start := nl + 1 + 8
+		fields[string(line)] = string(b[start : start+int(n)])
+		b = b[start+int(n)+1:]
+	}
+	return fields
+}
+
+func listenJournal(t *testing.T) (*net.UnixConn, string) {
+	t.Helper()
+	dir, err := os.MkdirTemp("", "loghq")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	path := filepath.Join(dir, "journal.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn, path
+}
+
+func TestJournalHandler(t *testing.T) {
+	srv, path := listenJournal(t)
+	h, err := NewJournalHandler(WithJournalSocket(path), WithJournalIdentifier("api"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h.Close()
+
+	logger := New(WithHandler(h), WithLevel(TraceLevel), WithStackLevel(FatalLevel))
+	logger.Named("orders").Success("order placed",
+		"order-id", 42,
+		"_private", "x",
+		"9lives", true,
+		"note", "line1\nline2",
+		"user", Group("", String("name", "ali")),
+		"message", "shadow",
+		"code_file", "fake.go",
+	)
+	logger.With(Group("http", Int("status", 201))).Trace("traced")
+
+	buf := make([]byte, 64*1024)
+	srv.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, err := srv.Read(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := parseJournal(t, buf[:n])
+	want := map[string]string{
+		"MESSAGE":           "order placed",
+		"PRIORITY":          "5",
+		"SYSLOG_IDENTIFIER": "api",
+		"LOGGER":            "orders",
+		"ORDER_ID":          "42",
+		"PRIVATE":           "x",
+		"LIVES":             "true",
+		"NOTE":              "line1\nline2",
+		"CODE_FUNC":         "TestJournalHandler",
+		"FIELD_MESSAGE":     "shadow",
+		"FIELD_CODE_FILE":   "fake.go",
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("%s = %q, want %q", k, got[k], v)
+		}
+	}
+	if !filepath.IsAbs(got["CODE_FILE"]) || !strings.HasSuffix(got["CODE_FILE"], "/loghq_test.go") || got["CODE_LINE"] == "" {
+		t.Errorf("missing code location: %v", got)
+	}
+
+	n, err = srv.Read(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got = parseJournal(t, buf[:n])
+	if got["PRIORITY"] != "7" || got["HTTP_STATUS"] != "201" {
+		t.Errorf("trace record: %v", got)
+	}
+}
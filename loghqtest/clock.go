@@ -0,0 +1,45 @@
+package loghqtest
+
+import (
+	"sync"
+	"time"
+
+	"github.com/Bhavyyadav25/loghq"
+)
+
+// FixedClock returns a clock that always reports t.
+//
+//	logger := loghq.New(loghq.WithClock(loghqtest.FixedClock(time.Unix(0, 0).UTC())))
+func FixedClock(t time.Time) loghq.Clock {
+	return loghq.ClockFunc(func() time.Time { return t })
+}
+
+// SteppingClock reports start, then advances by a fixed step on every
+// call, so consecutive records get distinct, predictable timestamps. It is
+// safe for concurrent use.
+type SteppingClock struct {
+	mu   sync.Mutex
+	next time.Time
+	step time.Duration
+}
+
+// NewSteppingClock returns a clock whose first reading is start.
+func NewSteppingClock(start time.Time, step time.Duration) *SteppingClock {
+	return &SteppingClock{next: start, step: step}
+}
+
+// Now returns the current reading and advances the clock.
+func (c *SteppingClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := c.next
+	c.next = c.next.Add(c.step)
+	return t
+}
+
+// Add moves the clock forward by d without consuming a reading.
+func (c *SteppingClock) Add(d time.Duration) {
+	c.mu.Lock()
+	c.next = c.next.Add(d)
+	c.mu.Unlock()
+}
@@ -0,0 +1,185 @@
+package loghqtest
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Bhavyyadav25/loghq"
+)
+
+func TestObservedHandler(t *testing.T) {
+	logger, logs := NewObserved(loghq.DebugLevel)
+
+	logger.Trace("dropped")
+	logger.Debug("starting", "attempt", 1)
+	logger.Named("billing").Warn("retrying", "status", 503, "err", errors.New("unavailable"))
+	logger.With(loghq.Group("req", loghq.String("id", "r-1"))).Error("charge failed", "status", 503)
+	logger.Info("done", "status", 200)
+
+	if logs.Len() != 4 {
+		t.Fatalf("Len() = %d, want 4: %v", logs.Len(), logs.Messages())
+	}
+	if got := logs.FilterLevel(loghq.WarnLevel).Messages(); len(got) != 1 || got[0] != "retrying" {
+		t.Errorf("FilterLevel(Warn) = %v", got)
+	}
+	if got := logs.FilterMessage("done").Len(); got != 1 {
+		t.Errorf("FilterMessage = %d", got)
+	}
+	if got := logs.FilterMessageSnippet("charge").Len(); got != 1 {
+		t.Errorf("FilterMessageSnippet = %d", got)
+	}
+	if got := logs.FilterField(loghq.Int("status", 503)).Messages(); len(got) != 2 {
+		t.Errorf("FilterField(status=503) = %v", got)
+	}
+	if got := logs.FilterField(loghq.Group("req", loghq.String("id", "r-1"))).Messages(); len(got) != 1 || got[0] != "charge failed" {
+		t.Errorf("FilterField(group) = %v", got)
+	}
+	if got := logs.FilterFieldKey("attempt").Len(); got != 1 {
+		t.Errorf("FilterFieldKey = %d", got)
+	}
+	if got := logs.FilterLogger("billing").Messages(); len(got) != 1 || got[0] != "retrying" {
+		t.Errorf("FilterLogger = %v", got)
+	}
+	if got := logs.FilterLevel(loghq.ErrorLevel).FilterField(loghq.Int("status", 503)).Len(); got != 1 {
+		t.Errorf("chained filters = %d", got)
+	}
+
+	recs := logs.All()
+	if !recs[2].Caller.Defined() || !strings.HasSuffix(recs[2].Caller.File, "loghqtest_test.go") {
+		t.Errorf("caller = %+v", recs[2].Caller)
+	}
+	if !strings.Contains(recs[2].Stack, "TestObservedHandler") {
+		t.Errorf("stack = %q", recs[2].Stack)
+	}
+
+	taken := logs.TakeAll()
+	if len(taken) != 4 || logs.Len() != 0 {
+		t.Errorf("TakeAll returned %d, left %d", len(taken), logs.Len())
+	}
+}
+
+func TestObservedHandlerDeepCopy(t *testing.T) {
+	logger, logs := NewObserved(loghq.InfoLevel)
+
+	b := []byte("abc")
+	fields := make([]loghq.Field, 20)
+	for i := range fields {
+		fields[i] = loghq.Int("n", i)
+	}
+	logger.With(fields...).Info("many", "raw", b)
+	b[0] = 'X'
+
+	// Reuse pooled records to make sure nothing is shared with them.
+	for i := 0; i < 10; i++ {
+		logger.Info("other", "k", i)
+	}
+
+	rec := logs.FilterMessage("many").All()[0]
+	if rec.NumFields() != 21 {
+		t.Fatalf("NumFields() = %d", rec.NumFields())
+	}
+	if f := rec.FieldAt(19); f.Ival != 19 {
+		t.Errorf("field 19 = %+v", f)
+	}
+	if f, _ := rec.Lookup("raw"); string(f.Iface.([]byte)) != "abc" {
+		t.Errorf("bytes field changed: %q", f.Iface)
+	}
+}
+
+func TestObservedHandlerConcurrent(t *testing.T) {
+	logger, logs := NewObserved(loghq.InfoLevel)
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				logger.Info("tick", "i", i)
+			}
+		}()
+	}
+	wg.Wait()
+	if logs.Len() != 800 {
+		t.Errorf("Len() = %d", logs.Len())
+	}
+}
+
+// fakeTB records Log calls and runs cleanups on demand.
+type fakeTB struct {
+	testing.TB
+	lines    []string
+	cleanups []func()
+}
+
+func (f *fakeTB) Helper()           {}
+func (f *fakeTB) Log(args ...any)   { f.lines = append(f.lines, args[0].(string)) }
+func (f *fakeTB) Cleanup(fn func()) { f.cleanups = append(f.cleanups, fn) }
+
+func (f *fakeTB) finish() {
+	for _, fn := range f.cleanups {
+		fn()
+	}
+}
+
+func TestTBHandler(t *testing.T) {
+	tb := &fakeTB{}
+	logger := NewLogger(tb, loghq.WithCaller(false))
+
+	logger.Debug("connecting", "addr", "localhost:5432")
+	logger.Named("db").Warn("slow query", "ms", 250)
+
+	if len(tb.lines) != 2 {
+		t.Fatalf("lines = %q", tb.lines)
+	}
+	if l := tb.lines[0]; !strings.Contains(l, "DEBUG") || !strings.Contains(l, "connecting") ||
+		!strings.Contains(l, "addr=localhost:5432") || strings.HasSuffix(l, "\n") || strings.Contains(l, "\033[") {
+		t.Errorf("line 0 = %q", l)
+	}
+	if l := tb.lines[1]; !strings.Contains(l, "[db]") || !strings.Contains(l, "ms=250") {
+		t.Errorf("line 1 = %q", l)
+	}
+
+	tb.finish()
+	logger.Info("after test")
+	if len(tb.lines) != 2 {
+		t.Errorf("logged after cleanup: %q", tb.lines[2:])
+	}
+}
+
+func TestTBHandlerLevel(t *testing.T) {
+	tb := &fakeTB{}
+	logger := loghq.New(loghq.WithLevel(loghq.TraceLevel), loghq.WithHandler(NewTBHandler(tb, loghq.WarnLevel)))
+	logger.Info("quiet")
+	logger.Error("loud")
+	if len(tb.lines) != 1 || !strings.Contains(tb.lines[0], "loud") {
+		t.Errorf("lines = %q", tb.lines)
+	}
+
+	// A real testing.T: output shows up under this test with -v.
+	NewLogger(t).Info("routed through t.Log")
+}
+
+func TestClocks(t *testing.T) {
+	start := time.Date(2025, 1, 30, 14, 32, 1, 0, time.UTC)
+	c := NewSteppingClock(start, time.Second)
+	if got := c.Now(); !got.Equal(start) {
+		t.Errorf("first reading = %v", got)
+	}
+	c.Add(time.Minute)
+	if got := c.Now(); !got.Equal(start.Add(time.Minute + time.Second)) {
+		t.Errorf("after Add = %v", got)
+	}
+
+	fixed := FixedClock(start)
+	logger, logs := NewObserved(loghq.InfoLevel, loghq.WithClock(fixed))
+	logger.Info("a")
+	logger.Info("b")
+	for _, rec := range logs.All() {
+		if !rec.Time.Equal(start) {
+			t.Errorf("%s: time = %v", rec.Message, rec.Time)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+// Package loghqtest provides handlers for testing code that logs with
+// loghq: ObservedHandler keeps records in memory for assertions, and
+// TBHandler routes output through testing.TB so it is attributed to the
+// test that produced it and shown only when the test fails or runs with -v.
+//
+//	logger, logs := loghqtest.NewObserved(loghq.DebugLevel)
+//	svc := NewService(logger)
+//	svc.Charge(ctx, 42)
+//	if got := logs.FilterMessage("charge failed").Len(); got != 0 {
+//		t.Errorf("unexpected failures: %d", got)
+//	}
+package loghqtest
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/Bhavyyadav25/loghq"
+)
+
+// ObservedHandler retains a deep copy of every record it handles. It is
+// safe for concurrent use. Filter methods return a new ObservedHandler
+// holding a snapshot of the matching records, so they can be chained:
+//
+//	logs.FilterLevel(loghq.WarnLevel).FilterField(loghq.Int("status", 503)).Len()
+type ObservedHandler struct {
+	level loghq.Level
+
+	mu      sync.Mutex
+	records []*loghq.Record
+}
+
+// NewObservedHandler returns a handler that keeps records at lvl and
+// above.
+func NewObservedHandler(lvl loghq.Level) *ObservedHandler {
+	return &ObservedHandler{level: lvl}
+}
+
+// NewObserved returns a logger at lvl writing to a new ObservedHandler.
+// opts are applied after the level and handler.
+func NewObserved(lvl loghq.Level, opts ...loghq.Option) (*loghq.Logger, *ObservedHandler) {
+	h := NewObservedHandler(lvl)
+	opts = append([]loghq.Option{loghq.WithLevel(lvl), loghq.WithHandler(h)}, opts...)
+	return loghq.New(opts...), h
+}
+
+func (h *ObservedHandler) Enabled(lvl loghq.Level) bool {
+	return lvl >= h.level
+}
+
+// Handle stores a deep copy of rec; the record itself is returned to its
+// pool after Handle returns.
+func (h *ObservedHandler) Handle(rec *loghq.Record) error {
+	c := rec.Clone()
+	h.mu.Lock()
+	h.records = append(h.records, c)
+	h.mu.Unlock()
+	return nil
+}
+
+// Len returns the number of records observed.
+func (h *ObservedHandler) Len() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.records)
+}
+
+// All returns the records observed so far, oldest first.
+func (h *ObservedHandler) All() []*loghq.Record {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]*loghq.Record(nil), h.records...)
+}
+
+// TakeAll returns the records observed so far and forgets them.
+func (h *ObservedHandler) TakeAll() []*loghq.Record {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	records := h.records
+	h.records = nil
+	return records
+}
+
+// Filter returns the records for which keep returns true.
+func (h *ObservedHandler) Filter(keep func(*loghq.Record) bool) *ObservedHandler {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	out := &ObservedHandler{level: h.level}
+	for _, rec := range h.records {
+		if keep(rec) {
+			out.records = append(out.records, rec)
+		}
+	}
+	return out
+}
+
+// FilterLevel returns the records logged at exactly lvl.
+func (h *ObservedHandler) FilterLevel(lvl loghq.Level) *ObservedHandler {
+	return h.Filter(func(rec *loghq.Record) bool { return rec.Level == lvl })
+}
+
+// FilterMessage returns the records whose message equals msg.
+func (h *ObservedHandler) FilterMessage(msg string) *ObservedHandler {
+	return h.Filter(func(rec *loghq.Record) bool { return rec.Message == msg })
+}
+
+// FilterMessageSnippet returns the records whose message contains snippet.
+func (h *ObservedHandler) FilterMessageSnippet(snippet string) *ObservedHandler {
+	return h.Filter(func(rec *loghq.Record) bool { return strings.Contains(rec.Message, snippet) })
+}
+
+// FilterField returns the records with a top-level field equal to f: same
+// key, type, and value. Key-value pairs are converted as the logger
+// converts them, so FilterField(loghq.Int("status", 200)) matches a
+// record logged with "status", 200.
+func (h *ObservedHandler) FilterField(f loghq.Field) *ObservedHandler {
+	return h.Filter(func(rec *loghq.Record) bool {
+		found := false
+		rec.EachField(func(rf *loghq.Field) {
+			if !found && FieldsEqual(*rf, f) {
+				found = true
+			}
+		})
+		return found
+	})
+}
+
+// FilterFieldKey returns the records with a top-level field named key.
+func (h *ObservedHandler) FilterFieldKey(key string) *ObservedHandler {
+	return h.Filter(func(rec *loghq.Record) bool {
+		_, ok := rec.Lookup(key)
+		return ok
+	})
+}
+
+// FilterLogger returns the records from the logger with the given Named
+// name.
+func (h *ObservedHandler) FilterLogger(name string) *ObservedHandler {
+	return h.Filter(func(rec *loghq.Record) bool { return rec.Name == name })
+}
+
+// Messages returns the messages of the records observed so far, oldest
+// first.
+func (h *ObservedHandler) Messages() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	msgs := make([]string, len(h.records))
+	for i, rec := range h.records {
+		msgs[i] = rec.Message
+	}
+	return msgs
+}
+
+// FieldsEqual reports whether a and b have the same key, type, and value.
+func FieldsEqual(a, b loghq.Field) bool {
+	return a.Key == b.Key && a.Type == b.Type && a.Ival == b.Ival && a.Str == b.Str &&
+		reflect.DeepEqual(a.Iface, b.Iface)
+}
@@ -0,0 +1,70 @@
+package loghqtest
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Bhavyyadav25/loghq"
+)
+
+// TBHandler writes each record with tb.Log, so output belongs to the test
+// that produced it and is only printed when that test fails or with -v.
+// Records use the console layout without color and with a short time.
+// The file:line that tb.Log prefixes points into loghq; the record's
+// caller field shows where it was logged.
+//
+// Records logged after the test completes, e.g. from a goroutine that
+// outlives it, are dropped rather than panicking in tb.Log.
+type TBHandler struct {
+	tb    testing.TB
+	level loghq.Level
+	enc   loghq.Encoder
+
+	mu   sync.Mutex
+	done bool
+}
+
+// NewTBHandler returns a handler logging records at lvl and above to tb.
+func NewTBHandler(tb testing.TB, lvl loghq.Level) *TBHandler {
+	h := &TBHandler{
+		tb:    tb,
+		level: lvl,
+		enc:   &loghq.ConsoleEncoder{NoColor: true, TimeLayout: "15:04:05.000"},
+	}
+	tb.Cleanup(func() {
+		h.mu.Lock()
+		h.done = true
+		h.mu.Unlock()
+	})
+	return h
+}
+
+// NewLogger returns a logger that logs every level to tb. opts are applied
+// after the level and handler.
+func NewLogger(tb testing.TB, opts ...loghq.Option) *loghq.Logger {
+	opts = append([]loghq.Option{
+		loghq.WithLevel(loghq.TraceLevel),
+		loghq.WithHandler(NewTBHandler(tb, loghq.TraceLevel)),
+	}, opts...)
+	return loghq.New(opts...)
+}
+
+func (h *TBHandler) Enabled(lvl loghq.Level) bool {
+	return lvl >= h.level
+}
+
+// Handle encodes rec and passes it to tb.Log.
+func (h *TBHandler) Handle(rec *loghq.Record) error {
+	var buf loghq.Buffer
+	h.enc.Encode(&buf, rec)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.done {
+		return nil
+	}
+	h.tb.Helper()
+	h.tb.Log(strings.TrimRight(string(buf.Bytes()), "\n"))
+	return nil
+}
@@ -0,0 +1,95 @@
+package loghq
+
+import "time"
+
+// ObjectMarshaler is implemented by types that describe their own fields.
+// Encoders hand MarshalLogObject a FieldEncoder bound to their output
+// buffer, so logging the value costs no reflection and, for pointer
+// receivers, no allocation.
+//
+//	func (u *User) MarshalLogObject(enc loghq.FieldEncoder) error {
+//		enc.EncodeString("name", u.Name)
+//		enc.EncodeInt64("id", u.ID)
+//		return nil
+//	}
+//
+// A returned error is recorded as an "error" key inside the object.
+type ObjectMarshaler interface {
+	MarshalLogObject(enc FieldEncoder) error
+}
+
+// ArrayMarshaler is implemented by collection types that append their own
+// elements. A returned error is appended as a final string element.
+type ArrayMarshaler interface {
+	MarshalLogArray(enc ArrayEncoder) error
+}
+
+// ArrayEncoder receives the elements of an ArrayMarshaler.
+type ArrayEncoder interface {
+	AppendString(val string)
+	AppendInt64(val int64)
+	AppendUint64(val uint64)
+	AppendFloat64(val float64)
+	AppendBool(val bool)
+	AppendDuration(val time.Duration)
+	AppendTime(val time.Time)
+	AppendAny(val interface{})
+	AppendObject(obj ObjectMarshaler)
+	AppendArray(arr ArrayMarshaler)
+}
+
+// ObjectMarshalerFunc adapts a function to ObjectMarshaler.
+type ObjectMarshalerFunc func(enc FieldEncoder) error
+
+func (f ObjectMarshalerFunc) MarshalLogObject(enc FieldEncoder) error { return f(enc) }
+
+// ArrayMarshalerFunc adapts a function to ArrayMarshaler.
+type ArrayMarshalerFunc func(enc ArrayEncoder) error
+
+func (f ArrayMarshalerFunc) MarshalLogArray(enc ArrayEncoder) error { return f(enc) }
+
+// Object logs a value that encodes its own fields.
+func Object(key string, val ObjectMarshaler) Field {
+	return Field{Key: key, Type: FieldObject, Iface: val}
+}
+
+// Array logs a collection that encodes its own elements.
+func Array(key string, val ArrayMarshaler) Field {
+	return Field{Key: key, Type: FieldArray, Iface: val}
+}
+
+// Typed slices used by Strings, Ints, Float64s, and Durations.
+type (
+	stringArray   []string
+	intArray      []int
+	float64Array  []float64
+	durationArray []time.Duration
+)
+
+func (a stringArray) MarshalLogArray(enc ArrayEncoder) error {
+	for _, v := range a {
+		enc.AppendString(v)
+	}
+	return nil
+}
+
+func (a intArray) MarshalLogArray(enc ArrayEncoder) error {
+	for _, v := range a {
+		enc.AppendInt64(int64(v))
+	}
+	return nil
+}
+
+func (a float64Array) MarshalLogArray(enc ArrayEncoder) error {
+	for _, v := range a {
+		enc.AppendFloat64(v)
+	}
+	return nil
+}
+
+func (a durationArray) MarshalLogArray(enc ArrayEncoder) error {
+	for _, v := range a {
+		enc.AppendDuration(v)
+	}
+	return nil
+}
@@ -0,0 +1,348 @@
+package loghq
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// NetConfig configures a NetWriter.
+type NetConfig struct {
+	// Network is "tcp", "tcp4", "tcp6", "udp", "udp4", "udp6", "unix", or
+	// "unixgram".
+	Network string
+
+	// Address is the collector address: "host:port" or a socket path.
+	Address string
+
+	// TLS enables TLS for TCP networks.
+	TLS *tls.Config
+
+	// DialTimeout bounds each connection attempt. Default: 5s.
+	DialTimeout time.Duration
+
+	// WriteTimeout bounds each write, so a stalled collector can't block
+	// the logger. Default: 1s.
+	WriteTimeout time.Duration
+
+	// BufferSize is how many bytes of messages are kept while disconnected.
+	// When full, the oldest messages are dropped. Default: 1MB.
+	BufferSize int
+
+	// MinBackoff and MaxBackoff bound the exponential delay between
+	// reconnect attempts. Defaults: 100ms and 30s.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+}
+
+func (c *NetConfig) dialTimeout() time.Duration {
+	if c.DialTimeout > 0 {
+		return c.DialTimeout
+	}
+	return 5 * time.Second
+}
+
+func (c *NetConfig) writeTimeout() time.Duration {
+	if c.WriteTimeout > 0 {
+		return c.WriteTimeout
+	}
+	return time.Second
+}
+
+func (c *NetConfig) bufferSize() int {
+	if c.BufferSize > 0 {
+		return c.BufferSize
+	}
+	return 1024 * 1024 // 1MB
+}
+
+func (c *NetConfig) minBackoff() time.Duration {
+	if c.MinBackoff > 0 {
+		return c.MinBackoff
+	}
+	return 100 * time.Millisecond
+}
+
+func (c *NetConfig) maxBackoff() time.Duration {
+	if c.MaxBackoff > 0 {
+		return c.MaxBackoff
+	}
+	return 30 * time.Second
+}
+
+// NetWriter implements WriteSyncer over a network or Unix socket. Each
+// Write is sent as one message (one datagram for udp and unixgram).
+//
+// Writes never wait for a connection: while disconnected, messages are
+// kept in a bounded in-memory buffer and a background goroutine redials
+// with exponential backoff, sending the buffer once it reconnects. Writes
+// on a live connection are bounded by WriteTimeout; a failed write drops
+// the connection and buffers the whole message, so the next connection
+// receives it intact even if part of it reached the old one.
+type NetWriter struct {
+	cfg  NetConfig
+	dial func() (net.Conn, error)
+
+	// sendMu serializes writes to the live connection so messages never
+	// interleave. It is never held with mu, so a stalled collector does
+	// not block buffering, Sync, or Close.
+	sendMu sync.Mutex
+
+	mu      sync.Mutex
+	conn    net.Conn
+	pending [][]byte
+	size    int
+	closed  bool
+
+	dropped atomic.Int64
+	kick    chan struct{}
+	done    chan struct{}
+	wg      sync.WaitGroup
+}
+
+// NewNetWriter creates a NetWriter and makes one connection attempt. A
+// collector that is not yet reachable is not an error: messages are
+// buffered until the background reconnect succeeds.
+func NewNetWriter(cfg NetConfig) (*NetWriter, error) {
+	switch cfg.Network {
+	case "tcp", "tcp4", "tcp6":
+	case "udp", "udp4", "udp6", "unix", "unixgram":
+		if cfg.TLS != nil {
+			return nil, fmt.Errorf("loghq: TLS is not supported over %s", cfg.Network)
+		}
+	default:
+		return nil, fmt.Errorf("loghq: unsupported network %q", cfg.Network)
+	}
+	if cfg.Address == "" {
+		return nil, fmt.Errorf("loghq: network address is required")
+	}
+
+	w := &NetWriter{
+		cfg:  cfg,
+		kick: make(chan struct{}, 1),
+		done: make(chan struct{}),
+	}
+	d := &net.Dialer{Timeout: cfg.dialTimeout()}
+	if cfg.TLS != nil {
+		w.dial = func() (net.Conn, error) {
+			return tls.DialWithDialer(d, cfg.Network, cfg.Address, cfg.TLS)
+		}
+	} else {
+		w.dial = func() (net.Conn, error) {
+			return d.Dial(cfg.Network, cfg.Address)
+		}
+	}
+
+	if conn, err := w.dial(); err == nil {
+		w.conn = conn
+	}
+	w.wg.Add(1)
+	go w.reconnectLoop()
+	if w.conn == nil {
+		w.reconnect()
+	}
+	return w, nil
+}
+
+// Write sends p, or buffers it while disconnected. It only fails after
+// Close.
+func (w *NetWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return 0, net.ErrClosed
+	}
+	conn := w.conn
+	if conn == nil {
+		w.buffer(p)
+		w.mu.Unlock()
+		return len(p), nil
+	}
+	w.mu.Unlock()
+
+	w.sendMu.Lock()
+	err := w.send(conn, p)
+	w.sendMu.Unlock()
+	if err != nil {
+		w.mu.Lock()
+		if w.conn == conn {
+			w.dropConn()
+		}
+		if !w.closed {
+			w.buffer(p)
+		}
+		w.mu.Unlock()
+	}
+	return len(p), nil
+}
+
+// Sync reports whether everything written so far has been sent. It
+// returns an error while messages are buffered for a lost connection.
+func (w *NetWriter) Sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.conn == nil && len(w.pending) > 0 {
+		return fmt.Errorf("loghq: %s %s: not connected, %d bytes buffered",
+			w.cfg.Network, w.cfg.Address, w.size)
+	}
+	return nil
+}
+
+// Close stops reconnecting and closes the connection. Messages still
+// buffered are discarded.
+func (w *NetWriter) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	close(w.done)
+	var err error
+	if w.conn != nil {
+		err = w.conn.Close()
+		w.conn = nil
+	}
+	w.pending, w.size = nil, 0
+	w.mu.Unlock()
+
+	w.wg.Wait()
+	return err
+}
+
+// Connected reports whether the writer currently has a live connection.
+func (w *NetWriter) Connected() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.conn != nil
+}
+
+// Dropped returns how many messages were discarded because the buffer was
+// full.
+func (w *NetWriter) Dropped() int64 {
+	return w.dropped.Load()
+}
+
+// send writes p under the write deadline.
+func (w *NetWriter) send(conn net.Conn, p []byte) error {
+	conn.SetWriteDeadline(time.Now().Add(w.cfg.writeTimeout()))
+	_, err := conn.Write(p)
+	return err
+}
+
+// buffer stores a copy of p. Called with mu held.
+func (w *NetWriter) buffer(p []byte) {
+	w.enqueue(append([]byte(nil), p...))
+}
+
+// enqueue appends p to the pending messages, dropping the oldest ones to
+// stay within BufferSize. Called with mu held.
+func (w *NetWriter) enqueue(p []byte) {
+	limit := w.cfg.bufferSize()
+	if len(p) > limit {
+		w.dropped.Add(1)
+		return
+	}
+	for w.size+len(p) > limit && len(w.pending) > 0 {
+		w.size -= len(w.pending[0])
+		w.pending[0] = nil
+		w.pending = w.pending[1:]
+		w.dropped.Add(1)
+	}
+	w.pending = append(w.pending, p)
+	w.size += len(p)
+}
+
+// requeue puts the unsent messages of a batch back ahead of those
+// buffered while it was being sent. batch[0] failed and is resent whole,
+// since part of it may have reached only the lost connection. Called with
+// mu held.
+func (w *NetWriter) requeue(batch [][]byte) {
+	if w.closed {
+		return
+	}
+	newer := w.pending
+	w.pending, w.size = nil, 0
+	for _, p := range append(batch, newer...) {
+		w.enqueue(p)
+	}
+}
+
+// dropConn closes the connection and wakes the reconnect loop. Called
+// with mu held.
+func (w *NetWriter) dropConn() {
+	w.conn.Close()
+	w.conn = nil
+	w.reconnect()
+}
+
+func (w *NetWriter) reconnect() {
+	select {
+	case w.kick <- struct{}{}:
+	default:
+	}
+}
+
+func (w *NetWriter) reconnectLoop() {
+	defer w.wg.Done()
+	for {
+		select {
+		case <-w.kick:
+		case <-w.done:
+			return
+		}
+
+		backoff := w.cfg.minBackoff()
+		for !w.tryConnect() {
+			select {
+			case <-time.After(backoff):
+			case <-w.done:
+				return
+			}
+			backoff = min(backoff*2, w.cfg.maxBackoff())
+		}
+	}
+}
+
+// tryConnect dials and, on success, sends the buffered messages before
+// making the connection live. The buffer is taken in batches and sent
+// without holding mu, so Write keeps buffering meanwhile; the connection
+// goes live once a batch leaves nothing behind. It reports false if the
+// attempt should be retried.
+func (w *NetWriter) tryConnect() bool {
+	conn, err := w.dial()
+	if err != nil {
+		return false
+	}
+
+	for {
+		w.mu.Lock()
+		if w.closed || w.conn != nil {
+			w.mu.Unlock()
+			conn.Close()
+			return true
+		}
+		if len(w.pending) == 0 {
+			w.pending = nil
+			w.conn = conn
+			w.mu.Unlock()
+			return true
+		}
+		batch := w.pending
+		w.pending, w.size = nil, 0
+		w.mu.Unlock()
+
+		for i, p := range batch {
+			if err := w.send(conn, p); err != nil {
+				conn.Close()
+				w.mu.Lock()
+				w.requeue(batch[i:])
+				w.mu.Unlock()
+				return false
+			}
+		}
+	}
+}
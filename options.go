@@ -6,7 +6,19 @@ type Option func(*Logger)
 // WithLevel sets the minimum log level.
 func WithLevel(l Level) Option {
 	return func(lg *Logger) {
-		lg.level.Store(int32(l))
+		lg.level = NewLevelVar(l)
+		lg.shareLevel = false
+	}
+}
+
+// WithLevelVar makes the logger read its level from v, which may be shared
+// with other loggers and handlers and changed at runtime. Unlike a level
+// set with WithLevel, v is also shared by every logger derived from this
+// one.
+func WithLevelVar(v *LevelVar) Option {
+	return func(lg *Logger) {
+		lg.level = v
+		lg.shareLevel = true
 	}
 }
 
@@ -31,9 +43,71 @@ func WithStackLevel(l Level) Option {
 	}
 }
 
+// WithClock sets the source of record timestamps. Default: time.Now. Use
+// a fixed or stepping clock to make output reproducible in tests.
+func WithClock(c Clock) Option {
+	return func(lg *Logger) {
+		lg.clock = c
+	}
+}
+
+// WithOnFatal replaces the flush and close that Fatal performs after its
+// record is handled. Fatal still calls the exit function (see WithExitFunc)
+// when fn returns, so fn only ends the call early by exiting or panicking
+// itself, which makes Fatal paths testable:
+//
+//	logger := loghq.New(loghq.WithOnFatal(func(l *loghq.Logger) {
+//		l.Flush()
+//		panic("fatal")
+//	}))
+func WithOnFatal(fn func(l *Logger)) Option {
+	return func(lg *Logger) {
+		lg.onFatal = fn
+	}
+}
+
+// WithExitFunc replaces os.Exit as the function that ends the process
+// after a Fatal record, keeping the default flush and close.
+func WithExitFunc(fn func(code int)) Option {
+	return func(lg *Logger) {
+		lg.exit = fn
+	}
+}
+
+// WithDevelopment enables development mode, in which DPanic panics after
+// logging.
+func WithDevelopment(on bool) Option {
+	return func(lg *Logger) {
+		lg.dev = on
+	}
+}
+
 // WithCallerSkip adds additional frames to skip when capturing caller info.
 func WithCallerSkip(skip int) Option {
 	return func(lg *Logger) {
 		lg.callerSkip = skip
 	}
 }
+
+// WithLevelRules shares per-component level overrides with other loggers.
+// By default each New logger gets its own empty LevelRules, as does one
+// given nil.
+func WithLevelRules(r *LevelRules) Option {
+	return func(lg *Logger) {
+		lg.rules = r
+		if r == nil {
+			lg.rules = NewLevelRules()
+		}
+	}
+}
+
+// WithContextExtractor registers a function that derives fields from the
+// logging context, such as W3CTraceExtractor. Extractors run in
+// registration order after the fields from ContextWithFields.
+func WithContextExtractor(fn ContextExtractor) Option {
+	return func(lg *Logger) {
+		// Copy on append: the slice may be shared with the logger this one
+		// was derived from by WithOptions.
+		lg.extractors = append(lg.extractors[:len(lg.extractors):len(lg.extractors)], fn)
+	}
+}
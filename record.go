@@ -1,6 +1,7 @@
 package loghq
 
 import (
+	"context"
 	"fmt"
 	"sync"
 	"time"
@@ -14,9 +15,14 @@ type Record struct {
 	Time    time.Time
 	Level   Level
 	Message string
+	Name    string // logger name set by Logger.Named; "" for the root
 	Caller  CallerInfo
 	Stack   string
 
+	// Context is the context passed to the log call, or nil. Handlers
+	// that forward records elsewhere pass it on.
+	Context context.Context
+
 	// Inline storage for up to 16 fields — zero allocation.
 	fields  [inlineFieldCap]Field
 	nFields int
@@ -45,14 +51,65 @@ func (r *Record) reset() {
 	r.Time = time.Time{}
 	r.Level = InfoLevel
 	r.Message = ""
+	r.Name = ""
 	r.Caller = CallerInfo{}
 	r.Stack = ""
+	r.Context = nil
 	r.nFields = 0
 	r.extra = r.extra[:0:0] // Reset length AND capacity to prevent pool memory bloat
 }
 
-// AddField appends a field to the record.
+// copyTo copies r into dst, reusing dst's overflow storage.
+func (r *Record) copyTo(dst *Record) {
+	extra := dst.extra[:0]
+	*dst = *r
+	dst.extra = append(extra, r.extra...)
+}
+
+// Clone returns a deep copy of r that is not pooled, so it stays valid
+// after Handle returns. Field slices, groups, and byte values are copied;
+// other values, such as Any and Object fields, are shared.
+func (r *Record) Clone() *Record {
+	c := &Record{
+		Time:    r.Time,
+		Level:   r.Level,
+		Message: r.Message,
+		Name:    r.Name,
+		Caller:  r.Caller,
+		Stack:   r.Stack,
+		Context: r.Context,
+	}
+	r.EachField(func(f *Field) {
+		c.AddField(cloneField(*f))
+	})
+	return c
+}
+
+func cloneField(f Field) Field {
+	switch f.Type {
+	case FieldGroup:
+		if fields, ok := f.Iface.([]Field); ok {
+			cp := make([]Field, len(fields))
+			for i := range fields {
+				cp[i] = cloneField(fields[i])
+			}
+			f.Iface = cp
+		}
+	case FieldBytes:
+		if b, ok := f.Iface.([]byte); ok && b != nil {
+			f.Iface = append([]byte(nil), b...)
+		}
+	}
+	return f
+}
+
+// AddField appends a field to the record. Lazy fields, including those
+// nested in groups, are evaluated here, so they are only computed for
+// records that are actually logged.
 func (r *Record) AddField(f Field) {
+	if f.Type == FieldLazy || f.Type == FieldGroup {
+		f = f.resolveLazy()
+	}
 	if r.nFields < inlineFieldCap {
 		r.fields[r.nFields] = f
 		r.nFields++
@@ -82,6 +139,16 @@ func (r *Record) FieldAt(i int) *Field {
 	return &r.extra[i-r.nFields]
 }
 
+// Lookup returns the first top-level field with the given key.
+func (r *Record) Lookup(key string) (*Field, bool) {
+	for i, n := 0, r.NumFields(); i < n; i++ {
+		if f := r.FieldAt(i); f.Key == key && f.Type != FieldNamespace {
+			return f, true
+		}
+	}
+	return nil, false
+}
+
 // AddKVPairs parses slog-style key-value pairs directly into the inline field
 // array. This avoids allocating an intermediate []Field slice.
 func (r *Record) AddKVPairs(kvs []interface{}) {
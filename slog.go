@@ -0,0 +1,200 @@
+package loghq
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+)
+
+// slog levels for loghq's extra severities. slog only defines Debug, Info,
+// Warn and Error; the remaining levels sit in the gaps so every loghq Level
+// maps to a distinct slog.Level and back.
+const (
+	SlogLevelTrace   = slog.Level(-8)
+	SlogLevelSuccess = slog.Level(2)
+	SlogLevelPanic   = slog.Level(10)
+	SlogLevelFatal   = slog.Level(12)
+)
+
+// SlogLevel converts a loghq Level to the equivalent slog.Level.
+func SlogLevel(l Level) slog.Level {
+	switch {
+	case l <= TraceLevel:
+		return SlogLevelTrace
+	case l == DebugLevel:
+		return slog.LevelDebug
+	case l == InfoLevel:
+		return slog.LevelInfo
+	case l == SuccessLevel:
+		return SlogLevelSuccess
+	case l == WarnLevel:
+		return slog.LevelWarn
+	case l == ErrorLevel:
+		return slog.LevelError
+	case l == PanicLevel:
+		return SlogLevelPanic
+	default:
+		return SlogLevelFatal
+	}
+}
+
+// LevelFromSlog converts a slog.Level to the nearest loghq Level. Levels
+// between the named slog levels round down, so slog.LevelWarn+1 is WarnLevel.
+func LevelFromSlog(l slog.Level) Level {
+	switch {
+	case l < slog.LevelDebug:
+		return TraceLevel
+	case l < slog.LevelInfo:
+		return DebugLevel
+	case l < SlogLevelSuccess:
+		return InfoLevel
+	case l < slog.LevelWarn:
+		return SuccessLevel
+	case l < slog.LevelError:
+		return WarnLevel
+	case l < SlogLevelPanic:
+		return ErrorLevel
+	case l < SlogLevelFatal:
+		return PanicLevel
+	default:
+		return FatalLevel
+	}
+}
+
+// SlogBridge implements slog.Handler on top of a loghq Handler, so libraries
+// that take a *slog.Logger write through loghq's encoders and sinks.
+//
+// Groups opened with WithGroup become namespaces and slog.Group attrs become
+// Group fields, so encoders nest or flatten them as usual. Fields attached
+// with ContextWithFields are included when a context is passed to the slog
+// call.
+type SlogBridge struct {
+	handler Handler
+	fields  []Field
+}
+
+// NewSlogBridge creates a slog.Handler that forwards records to h.
+func NewSlogBridge(h Handler) *SlogBridge {
+	return &SlogBridge{handler: h}
+}
+
+// NewSlogLogger returns a *slog.Logger backed by h.
+func NewSlogLogger(h Handler) *slog.Logger {
+	return slog.New(NewSlogBridge(h))
+}
+
+// Enabled reports whether the wrapped handler accepts the mapped level.
+func (b *SlogBridge) Enabled(_ context.Context, lvl slog.Level) bool {
+	return b.handler.Enabled(LevelFromSlog(lvl))
+}
+
+// Handle converts the slog.Record into a pooled Record and forwards it.
+func (b *SlogBridge) Handle(ctx context.Context, r slog.Record) error {
+	rec := acquireRecord()
+	rec.Time = r.Time
+	rec.Level = LevelFromSlog(r.Level)
+	rec.Message = r.Message
+	rec.Context = ctx
+
+	if r.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+		rec.Caller = newCallerInfo(frame.File, frame.Line, frame.Function)
+		rec.Caller.PC = r.PC
+	}
+
+	rec.AddFields(fieldsFromContext(ctx))
+	rec.AddFields(b.fields)
+	var attrBuf [inlineFieldCap]Field
+	attrs := attrBuf[:0]
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = appendSlogAttr(attrs, a)
+		return true
+	})
+	rec.AddFields(attrs)
+
+	err := b.handler.Handle(rec)
+	releaseRecord(rec)
+	return err
+}
+
+// WithAttrs returns a bridge with the attrs pre-bound under the current group.
+func (b *SlogBridge) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return b
+	}
+	c := b.clone()
+	for _, a := range attrs {
+		c.fields = appendSlogAttr(c.fields, a)
+	}
+	return c
+}
+
+// WithGroup returns a bridge that nests subsequent attrs under name.
+func (b *SlogBridge) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return b
+	}
+	c := b.clone()
+	c.fields = append(c.fields, Namespace(name))
+	return c
+}
+
+func (b *SlogBridge) clone() *SlogBridge {
+	c := &SlogBridge{handler: b.handler}
+	if len(b.fields) > 0 {
+		c.fields = make([]Field, len(b.fields))
+		copy(c.fields, b.fields)
+	}
+	return c
+}
+
+// appendSlogAttr converts a slog.Attr to Fields following the slog.Handler
+// rules: empty attrs and groups are dropped, and groups with an empty key
+// are inlined.
+func appendSlogAttr(dst []Field, a slog.Attr) []Field {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return dst
+	}
+	if a.Value.Kind() != slog.KindGroup {
+		return append(dst, fieldFromSlogValue(a.Key, a.Value))
+	}
+
+	group := a.Value.Group()
+	if len(group) == 0 {
+		return dst
+	}
+	if a.Key == "" {
+		for _, ga := range group {
+			dst = appendSlogAttr(dst, ga)
+		}
+		return dst
+	}
+	fields := make([]Field, 0, len(group))
+	for _, ga := range group {
+		fields = appendSlogAttr(fields, ga)
+	}
+	return append(dst, Group(a.Key, fields...))
+}
+
+// fieldFromSlogValue maps a resolved, non-group slog.Value to a typed Field.
+func fieldFromSlogValue(key string, v slog.Value) Field {
+	switch v.Kind() {
+	case slog.KindString:
+		return String(key, v.String())
+	case slog.KindInt64:
+		return Int64(key, v.Int64())
+	case slog.KindUint64:
+		return Uint64(key, v.Uint64())
+	case slog.KindFloat64:
+		return Float64(key, v.Float64())
+	case slog.KindBool:
+		return Bool(key, v.Bool())
+	case slog.KindDuration:
+		return Duration(key, v.Duration())
+	case slog.KindTime:
+		return Time(key, v.Time())
+	default:
+		return toField(key, v.Any())
+	}
+}
@@ -0,0 +1,130 @@
+package loghq
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+)
+
+// SpanContext is a W3C trace context: the identifiers carried by the
+// traceparent header. Its field types match OpenTelemetry's TraceID,
+// SpanID, and TraceFlags, so an OTel span context converts directly:
+//
+//	sc := trace.SpanContextFromContext(ctx)
+//	tc := loghq.SpanContext{TraceID: sc.TraceID(), SpanID: sc.SpanID(), Flags: byte(sc.TraceFlags())}
+type SpanContext struct {
+	TraceID [16]byte
+	SpanID  [8]byte
+	Flags   byte
+}
+
+// IsValid reports whether both identifiers are non-zero.
+func (tc SpanContext) IsValid() bool {
+	return tc.TraceID != [16]byte{} && tc.SpanID != [8]byte{}
+}
+
+// Sampled reports whether the sampled flag is set.
+func (tc SpanContext) Sampled() bool {
+	return tc.Flags&1 == 1
+}
+
+// Traceparent formats tc as a version 00 traceparent header value.
+func (tc SpanContext) Traceparent() string {
+	var b [55]byte
+	copy(b[:], "00-")
+	hex.Encode(b[3:35], tc.TraceID[:])
+	b[35] = '-'
+	hex.Encode(b[36:52], tc.SpanID[:])
+	b[52] = '-'
+	hex.Encode(b[53:55], []byte{tc.Flags})
+	return string(b[:])
+}
+
+// ParseTraceparent parses a traceparent header value such as
+// "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01". Future
+// versions are accepted as long as they start with the version 00 layout.
+func ParseTraceparent(s string) (SpanContext, error) {
+	var tc SpanContext
+	if len(s) < 55 || s[2] != '-' || s[35] != '-' || s[52] != '-' || (len(s) > 55 && s[55] != '-') {
+		return tc, fmt.Errorf("loghq: invalid traceparent %q", s)
+	}
+	var version [1]byte
+	if _, err := hex.Decode(version[:], []byte(s[:2])); err != nil || version[0] == 0xff ||
+		(version[0] == 0 && len(s) != 55) {
+		return tc, fmt.Errorf("loghq: invalid traceparent version %q", s[:2])
+	}
+	var flags [1]byte
+	if _, err := hex.Decode(tc.TraceID[:], []byte(s[3:35])); err != nil {
+		return tc, fmt.Errorf("loghq: invalid trace id in %q", s)
+	}
+	if _, err := hex.Decode(tc.SpanID[:], []byte(s[36:52])); err != nil {
+		return tc, fmt.Errorf("loghq: invalid span id in %q", s)
+	}
+	if _, err := hex.Decode(flags[:], []byte(s[53:55])); err != nil {
+		return tc, fmt.Errorf("loghq: invalid trace flags in %q", s)
+	}
+	tc.Flags = flags[0]
+	if !tc.IsValid() {
+		return tc, fmt.Errorf("loghq: all-zero trace or span id in %q", s)
+	}
+	return tc, nil
+}
+
+type ctxTraceKey struct{}
+
+// traceValue stores the trace context with its fields pre-rendered, so
+// extracting them costs no allocation per log call.
+type traceValue struct {
+	tc     SpanContext
+	fields []Field
+}
+
+// ContextWithSpanContext returns a context carrying tc. W3CTraceExtractor
+// reads it back as trace_id, span_id, and trace_flags fields.
+func ContextWithSpanContext(ctx context.Context, tc SpanContext) context.Context {
+	return context.WithValue(ctx, ctxTraceKey{}, &traceValue{tc: tc, fields: traceFields(tc)})
+}
+
+// SpanContextFromContext returns the trace context stored by
+// ContextWithSpanContext.
+func SpanContextFromContext(ctx context.Context) (SpanContext, bool) {
+	if v, ok := ctx.Value(ctxTraceKey{}).(*traceValue); ok {
+		return v.tc, true
+	}
+	return SpanContext{}, false
+}
+
+// W3CTraceExtractor adds trace_id, span_id, and trace_flags fields from a
+// context prepared with ContextWithSpanContext:
+//
+//	logger := loghq.New(loghq.WithContextExtractor(loghq.W3CTraceExtractor))
+//	ctx = loghq.ContextWithSpanContext(ctx, tc)
+//	logger.WithContext(ctx).Info("charged")
+func W3CTraceExtractor(ctx context.Context) []Field {
+	if v, ok := ctx.Value(ctxTraceKey{}).(*traceValue); ok {
+		return v.fields
+	}
+	return nil
+}
+
+// TraceExtractor adapts a function that finds a trace context, such as a
+// wrapper around OpenTelemetry's trace.SpanContextFromContext, into a
+// ContextExtractor producing trace_id, span_id, and trace_flags fields.
+// Invalid trace contexts produce no fields.
+func TraceExtractor(get func(ctx context.Context) (SpanContext, bool)) ContextExtractor {
+	return func(ctx context.Context) []Field {
+		tc, ok := get(ctx)
+		if !ok || !tc.IsValid() {
+			return nil
+		}
+		return traceFields(tc)
+	}
+}
+
+func traceFields(tc SpanContext) []Field {
+	return []Field{
+		String("trace_id", hex.EncodeToString(tc.TraceID[:])),
+		String("span_id", hex.EncodeToString(tc.SpanID[:])),
+		String("trace_flags", hex.EncodeToString([]byte{tc.Flags})),
+	}
+}